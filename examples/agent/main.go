@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type WeatherArgs struct {
+	City string `json:"city" jsonschema:"description=Name of the city"`
+}
+
+type Weather struct {
+	TemperatureC float64 `json:"temperature_c"`
+	Conditions   string  `json:"conditions"`
+}
+
+var forecasts = map[string]Weather{
+	"oslo":   {TemperatureC: -3, Conditions: "snow"},
+	"lisbon": {TemperatureC: 17, Conditions: "sunny"},
+}
+
+func weather(ctx context.Context, args WeatherArgs) (Weather, error) {
+	forecast, ok := forecasts[strings.ToLower(args.City)]
+	if !ok {
+		return Weather{}, fmt.Errorf("no forecast for %s", args.City)
+	}
+	return forecast, nil
+}
+
+type Recommendation struct {
+	City   string `json:"city"   jsonschema:"description=The city to travel to"`
+	Reason string `json:"reason" jsonschema:"description=Why the city was picked"`
+}
+
+func main() {
+	ctx := context.Background()
+
+	client := instructor.FromOpenAI(
+		openai.NewClient(os.Getenv("OPENAI_API_KEY")),
+		instructor.WithMode(instructor.ModeToolCall),
+		instructor.WithMaxRetries(3),
+	)
+
+	recommendation, _, err := instructor.RunAgent[Recommendation](ctx, client, openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: "I want to go somewhere warm this weekend, Oslo or Lisbon?",
+			},
+		},
+	},
+		5,
+		instructor.NewTool("weather", "Get the weather forecast for a city", weather),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%+v\n", recommendation)
+	/*
+		{City:Lisbon Reason:Lisbon is forecast to be sunny at 17°C, while Oslo expects snow at -3°C.}
+	*/
+}
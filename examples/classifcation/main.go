@@ -17,8 +17,13 @@ const (
 	LabelGeneralQuery LabelType = "general_query"
 )
 
+// Enum lists the allowed labels for the schema and for validating the response.
+func (LabelType) Enum() []any {
+	return []any{LabelTechIssue, LabelBilling, LabelGeneralQuery}
+}
+
 type Label struct {
-	Type LabelType `json:"type" jsonschema:"title=Label type,description=Type of the label,enum=tech_issue,enum=billing,enum=general_query"`
+	Type LabelType `json:"type" jsonschema:"title=Label type,description=Type of the label"`
 }
 
 type Prediction struct {
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type Person struct {
+	Name string `json:"name" jsonschema:"title=the name,description=The name of the person"`
+	Age  int    `json:"age"  jsonschema:"title=the age,description=The age of the person"`
+}
+
+type Hobbies struct {
+	Hobbies []string `json:"hobbies" jsonschema:"description=The hobbies of the person"`
+}
+
+func main() {
+	ctx := context.Background()
+
+	client := instructor.FromOpenAI(
+		openai.NewClient(os.Getenv("OPENAI_API_KEY")),
+		instructor.WithMode(instructor.ModeToolCall),
+		instructor.WithMaxRetries(3),
+	)
+
+	conversation := instructor.NewConversation(client, openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: "Robby is 22 years old. He spends his weekends climbing and playing chess.",
+			},
+		},
+	})
+
+	person, _, err := instructor.Ask[Person](ctx, conversation, "Who is described?")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%+v\n", person)
+
+	hobbies, _, err := instructor.Ask[Hobbies](ctx, conversation, "What does he like to do?")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("%+v\n", hobbies)
+	/*
+		{Name:Robby Age:22}
+		{Hobbies:[climbing chess]}
+	*/
+}
@@ -27,9 +27,27 @@ func (s *Search) execute() {
 	fmt.Printf("Searching for `%s` with query `%s` using `%s`\n", s.Topic, s.Query, s.Type)
 }
 
-type Searches = []Search
+type Lookup struct {
+	Term string `json:"term" jsonschema:"title=Term,description=Term to look up in the encyclopedia,example=felis catus"`
+}
+
+func (l *Lookup) execute() {
+	fmt.Printf("Looking up `%s`\n", l.Term)
+}
 
-func segment(ctx context.Context, data string) *Searches {
+type Calculate struct {
+	Expression string `json:"expression" jsonschema:"title=Expression,description=Arithmetic expression to evaluate,example=2 * 3"`
+}
+
+func (c *Calculate) execute() {
+	fmt.Printf("Calculating `%s`\n", c.Expression)
+}
+
+type Action interface {
+	execute()
+}
+
+func plan(ctx context.Context, data string) []Action {
 
 	client := instructor.FromOpenAI(
 		openai.NewClient(os.Getenv("OPENAI_API_KEY")),
@@ -37,36 +55,41 @@ func segment(ctx context.Context, data string) *Searches {
 		instructor.WithMaxRetries(3),
 	)
 
-	var searches Searches
-	_, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+	results, _, err := instructor.CreateParallel(ctx, client, openai.ChatCompletionRequest{
 		Model: openai.GPT4o,
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleUser,
-				Content: fmt.Sprintf("Consider the data below: '\n%s' and segment it into multiple search queries", data),
+				Content: fmt.Sprintf("Consider the data below: '\n%s' and break it down into actions", data),
 			},
 		},
 	},
-		&searches,
+		Search{}, Lookup{}, Calculate{},
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	return &searches
+	actions := make([]Action, len(results))
+	for i, result := range results {
+		actions[i] = result.(Action)
+	}
+
+	return actions
 }
 
 func main() {
 	ctx := context.Background()
 
-	q := "Search for a picture of a cat, a video of a dog, and the taxonomy of each"
-	for _, search := range *segment(ctx, q) {
-		search.execute()
+	q := "Search for a picture of a cat and a video of a dog, look up the taxonomy of each, and work out how many legs they have together"
+	for _, action := range plan(ctx, q) {
+		action.execute()
 	}
 	/*
 		Searching for `cat` with query `picture of a cat` using `image`
 		Searching for `dog` with query `video of a dog` using `video`
-		Searching for `cat` with query `taxonomy of a cat` using `web`
-		Searching for `dog` with query `taxonomy of a dog` using `web`
+		Looking up `cat taxonomy`
+		Looking up `dog taxonomy`
+		Calculating `4 + 4`
 	*/
 }
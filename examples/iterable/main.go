@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+	anthropic "github.com/liushuangls/go-anthropic/v2"
+)
+
+type Person struct {
+	Name string `json:"name" jsonschema:"description=The name of the person"`
+	Age  int    `json:"age"  jsonschema:"description=The age of the person" validate:"gte=0,lte=130"`
+}
+
+func main() {
+	ctx := context.Background()
+
+	client := instructor.FromAnthropic(
+		anthropic.NewClient(os.Getenv("ANTHROPIC_API_KEY")),
+		instructor.WithMode(instructor.ModeToolCall),
+		instructor.WithMaxRetries(3),
+		instructor.WithValidation(),
+	)
+
+	people, _, err := instructor.CreateIterable[Person](ctx, client, anthropic.MessagesRequest{
+		Model: anthropic.ModelClaude3Haiku20240307,
+		Messages: []anthropic.Message{
+			anthropic.NewUserTextMessage("Extract everyone: Robby is 22, his sister Anna turned 19 last week and their grandfather Joe is 81."),
+		},
+		MaxTokens: 500,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, person := range people {
+		fmt.Printf("%s is %d years old\n", person.Name, person.Age)
+	}
+	/*
+		Robby is 22 years old
+		Anna is 19 years old
+		Joe is 81 years old
+	*/
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type Person struct {
+	Name string `json:"name" jsonschema:"description=The name of the person"`
+	Age  int    `json:"age"  jsonschema:"description=The age of the person"`
+}
+
+func extract(ctx context.Context, client *instructor.InstructorOpenAI, text string) {
+	var maybe instructor.Maybe[Person]
+	_, err := client.CreateChatCompletion(
+		ctx,
+		openai.ChatCompletionRequest{
+			Model: openai.GPT4o,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: "Extract the person from: " + text,
+				},
+			},
+		},
+		&maybe,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	person, found, reason := maybe.Unwrap()
+	if !found {
+		fmt.Printf("No person found: %s\n", reason)
+		return
+	}
+	fmt.Printf("Found %s, %d years old\n", person.Name, person.Age)
+}
+
+func main() {
+	ctx := context.Background()
+
+	client := instructor.FromOpenAI(
+		openai.NewClient(os.Getenv("OPENAI_API_KEY")),
+		instructor.WithMode(instructor.ModeToolCall),
+		instructor.WithMaxRetries(3),
+	)
+
+	extract(ctx, client, "Robby is 22 years old.")
+	extract(ctx, client, "The weather is nice today.")
+	/*
+		Found Robby, 22 years old
+		No person found: The text does not mention any person.
+	*/
+}
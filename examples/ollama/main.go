@@ -5,7 +5,7 @@ import (
 	"fmt"
 
 	"github.com/binarycraft007/instructor-go/pkg/instructor"
-	openai "github.com/sashabaranov/go-openai"
+	"github.com/binarycraft007/instructor-go/pkg/instructor/ollama"
 )
 
 type Character struct {
@@ -31,24 +31,23 @@ Facts:
 func main() {
 	ctx := context.Background()
 
-	config := openai.DefaultConfig("ollama")
-	config.BaseURL = "http://localhost:11434/v1"
-
-	client := instructor.FromOpenAI(
-		openai.NewClientWithConfig(config),
-		instructor.WithMode(instructor.ModeJSON),
+	client := instructor.FromOllama(
+		ollama.NewClient(),
+		instructor.WithMode(instructor.ModeJSONSchema),
 		instructor.WithMaxRetries(3),
 	)
 
 	var character Character
-	_, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: "llama3",
-		Messages: []openai.ChatCompletionMessage{
+	_, err := client.Chat(ctx, &ollama.ChatRequest{
+		Model: "llama3.1",
+		Messages: []ollama.Message{
 			{
-				Role:    openai.ChatMessageRoleUser,
+				Role:    ollama.RoleUser,
 				Content: "Tell me about the Hal 9000",
 			},
 		},
+		Options:   map[string]any{"num_ctx": 8192},
+		KeepAlive: "10m",
 	},
 		&character,
 	)
@@ -48,6 +48,7 @@ func main() {
 		Parts: []genai.Part{
 			genai.Text("Tell me about the history of artificial intelligence up to year 2000"),
 		},
+		ModelName: "gemini-1.5-flash",
 	},
 		*new(HistoricalFact),
 	)
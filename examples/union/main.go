@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type Document interface {
+	isDocument()
+}
+
+type Invoice struct {
+	Number string  `json:"number" jsonschema:"description=Invoice number"`
+	Total  float64 `json:"total"  jsonschema:"description=Amount due"`
+}
+
+type Receipt struct {
+	Store string  `json:"store" jsonschema:"description=Name of the store"`
+	Total float64 `json:"total" jsonschema:"description=Amount paid"`
+}
+
+type Letter struct {
+	Sender  string `json:"sender"`
+	Subject string `json:"subject"`
+}
+
+func (Invoice) isDocument() {}
+func (Receipt) isDocument() {}
+func (Letter) isDocument()  {}
+
+func main() {
+	ctx := context.Background()
+
+	err := instructor.RegisterUnion[Document]("kind", Invoice{}, Receipt{}, Letter{})
+	if err != nil {
+		panic(err)
+	}
+
+	client := instructor.FromOpenAI(
+		openai.NewClient(os.Getenv("OPENAI_API_KEY")),
+		instructor.WithMode(instructor.ModeToolCall),
+		instructor.WithMaxRetries(3),
+	)
+
+	var document Document
+	_, err = client.CreateChatCompletion(
+		ctx,
+		openai.ChatCompletionRequest{
+			Model: openai.GPT4o,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: "Classify and extract: ACME Corp. Invoice #2024-117. Total due: $1,250.00",
+				},
+			},
+		},
+		&document,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	switch doc := document.(type) {
+	case Invoice:
+		fmt.Printf("Invoice %s: %.2f\n", doc.Number, doc.Total)
+	case Receipt:
+		fmt.Printf("Receipt from %s: %.2f\n", doc.Store, doc.Total)
+	case Letter:
+		fmt.Printf("Letter from %s: %s\n", doc.Sender, doc.Subject)
+	}
+	/*
+		Invoice 2024-117: 1250.00
+	*/
+}
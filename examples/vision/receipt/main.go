@@ -109,7 +109,7 @@ func main() {
 			fmt.Printf("Error: %v\n", err)
 			continue
 		}
-		fmt.Println("\n--------------------------------\n")
+		fmt.Print("\n--------------------------------\n\n")
 	}
 	/*
 	   Receipt:
@@ -1,14 +1,19 @@
 module github.com/binarycraft007/instructor-go
 
-go 1.21.8
+go 1.22
 
 require (
+	github.com/aws/aws-sdk-go-v2 v1.38.3
+	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.7.1
+	github.com/aws/aws-sdk-go-v2/service/bedrockruntime v1.39.0
 	github.com/cohere-ai/cohere-go/v2 v2.8.1
 	github.com/go-playground/validator/v10 v10.21.0
 	github.com/google/generative-ai-go v0.18.0
 	github.com/invopop/jsonschema v0.12.0
-	github.com/liushuangls/go-anthropic/v2 v2.1.0
-	github.com/sashabaranov/go-openai v1.29.0
+	github.com/liushuangls/go-anthropic/v2 v2.10.0
+	github.com/sashabaranov/go-openai v1.29.2
+	github.com/wk8/go-ordered-map/v2 v2.1.8
+	golang.org/x/oauth2 v0.21.0
 	google.golang.org/api v0.186.0
 )
 
@@ -19,6 +24,9 @@ require (
 	cloud.google.com/go/auth/oauth2adapt v0.2.2 // indirect
 	cloud.google.com/go/compute/metadata v0.3.0 // indirect
 	cloud.google.com/go/longrunning v0.5.7 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.4.6 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.7.6 // indirect
+	github.com/aws/smithy-go v1.23.0 // indirect
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
 	github.com/buger/jsonparser v1.1.1 // indirect
 	github.com/felixge/httpsnoop v1.0.4 // indirect
@@ -35,7 +43,6 @@ require (
 	github.com/googleapis/gax-go/v2 v2.12.5 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
-	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
 	go.opencensus.io v0.24.0 // indirect
 	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.51.0 // indirect
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.51.0 // indirect
@@ -44,7 +51,6 @@ require (
 	go.opentelemetry.io/otel/trace v1.26.0 // indirect
 	golang.org/x/crypto v0.24.0 // indirect
 	golang.org/x/net v0.26.0 // indirect
-	golang.org/x/oauth2 v0.21.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/sys v0.21.0 // indirect
 	golang.org/x/text v0.16.0 // indirect
@@ -0,0 +1,240 @@
+package instructor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/invopop/jsonschema"
+)
+
+// Tool is a Go function the model can call in RunAgent before it gives the
+// final answer. Create one with NewTool.
+type Tool struct {
+	name        string
+	description string
+	args        reflect.Type
+	call        func(ctx context.Context, args any) (any, error)
+}
+
+// NewTool exposes fn to the model as a tool. The parameters of the tool are the
+// schema of A, usually a struct, and the result of fn is returned to the model
+// as JSON, or as is for strings. Errors returned by fn are reported to the
+// model, which may call the tool again.
+//
+//	search := instructor.NewTool("search", "Search the web", func(ctx context.Context, args SearchArgs) ([]Result, error) {
+//		...
+//	})
+func NewTool[A any, R any](name string, description string, fn func(ctx context.Context, args A) (R, error)) Tool {
+	return Tool{
+		name:        name,
+		description: description,
+		args:        reflect.TypeOf((*A)(nil)).Elem(),
+		call: func(ctx context.Context, args any) (any, error) {
+			return fn(ctx, *args.(*A))
+		},
+	}
+}
+
+// Name returns the name the model calls the tool by.
+func (t Tool) Name() string {
+	return t.name
+}
+
+// toolResult is the outcome of a tool call, as reported to the model.
+type toolResult struct {
+	content string
+	isError bool
+}
+
+// ErrMaxSteps is returned by RunAgent when the model hasn't given its final
+// answer within the step limit.
+var ErrMaxSteps = errors.New("hit max agent steps")
+
+// RunAgent lets the model call tools until it gives its final answer of type
+// T. Every step is one request: the tools the model called are run in order,
+// and their results appended to the conversation for the next step. The run
+// ends when the model calls the final answer tool, named after T, with a
+// valid answer, or with ErrMaxSteps after maxSteps requests.
+//
+// request is the provider request as passed to the client and the returned
+// response is the last provider response, with the usage of all steps.
+// Requires ModeToolCall or ModeToolCallStrict with OpenAI, Anthropic, Mistral
+// or Bedrock.
+func RunAgent[T any](ctx context.Context, i Instructor, request interface{}, maxSteps int, tools ...Tool) (T, interface{}, error) {
+	var answer T
+
+	if err := i.Err(); err != nil {
+		return answer, nil, err
+	}
+
+	switch i.Provider() {
+	case ProviderOpenAI, ProviderAnthropic, ProviderMistral, ProviderBedrock:
+	default:
+		return answer, nil, fmt.Errorf("agents are not supported for %s", i.Provider())
+	}
+	switch mode := i.requestMode(request, false); mode {
+	case ModeToolCall, ModeToolCallStrict:
+	default:
+		return answer, nil, fmt.Errorf("agents require mode %s or %s, got %s", ModeToolCall, ModeToolCallStrict, mode)
+	}
+
+	schema, final, err := newAgentSchema(reflect.TypeOf(answer), tools)
+	if err != nil {
+		return answer, nil, err
+	}
+
+	byName := make(map[string]Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.name] = tool
+	}
+
+	if i.Validate() {
+		validate = validator.New()
+	}
+
+	// keep a running total of usage
+	usage := &UsageSum{}
+
+	for step := 0; step < maxSteps; step++ {
+
+		reservation, err := i.RateLimiter().wait(ctx, estimateTokens(request, schema))
+		if err != nil {
+			return answer, i.emptyResponseWithUsageSum(usage), err
+		}
+
+		text, resp, err := i.chat(ctx, request, schema)
+		if err != nil {
+			return answer, i.emptyResponseWithResponseUsage(resp), err
+		}
+
+		i.RateLimiter().record(reservation, i.countUsageFromResponse(resp, &UsageSum{}))
+
+		var calls []parallelCall
+		if err := json.Unmarshal([]byte(text), &calls); err != nil {
+			return answer, i.emptyResponseWithResponseUsage(resp), err
+		}
+
+		results := make([]toolResult, len(calls))
+		done := false
+
+		for idx, call := range calls {
+			if call.Name == final {
+				if err := decodeArguments(ctx, i, call.Arguments, &answer); err != nil {
+					results[idx] = toolResult{content: fmt.Sprintf("Invalid answer: %s", err), isError: true}
+					continue
+				}
+				results[idx] = toolResult{content: "Answer accepted."}
+				done = true
+				continue
+			}
+
+			tool, ok := byName[call.Name]
+			if !ok {
+				results[idx] = toolResult{content: fmt.Sprintf("Unknown tool %q.", call.Name), isError: true}
+				continue
+			}
+
+			results[idx] = tool.run(ctx, i, call.Arguments)
+		}
+
+		if done {
+			response, err := i.addUsageSumToResponse(resp, usage)
+			return answer, response, err
+		}
+
+		i.countUsageFromResponse(resp, usage)
+
+		request = i.addToolResults(request, calls, results)
+	}
+
+	return answer, i.emptyResponseWithUsageSum(usage), ErrMaxSteps
+}
+
+// run decodes the arguments of a call and runs the tool.
+func (t Tool) run(ctx context.Context, i Instructor, arguments json.RawMessage) toolResult {
+	args := reflect.New(t.args).Interface()
+	if err := decodeArguments(ctx, i, arguments, args); err != nil {
+		return toolResult{content: fmt.Sprintf("Invalid arguments: %s", err), isError: true}
+	}
+
+	out, err := t.call(ctx, args)
+	if err != nil {
+		return toolResult{content: err.Error(), isError: true}
+	}
+
+	if s, ok := out.(string); ok {
+		return toolResult{content: s}
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return toolResult{content: fmt.Sprintf("Result could not be encoded: %s", err), isError: true}
+	}
+	return toolResult{content: string(b)}
+}
+
+// newAgentSchema returns the tools of an agent, followed by the final answer
+// tool for t, and the name of the latter.
+func newAgentSchema(t reflect.Type, tools []Tool) (*Schema, string, error) {
+	ts, err := schemas.schema(t)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(ts.Functions) != 1 {
+		return nil, "", fmt.Errorf("final answer type %s must expose a single tool, got %d", t, len(ts.Functions))
+	}
+
+	answer := ts.Functions[0]
+	answer.Description = appendDescription(answer.Description, "Call this with the final answer once you have everything you need.")
+
+	s := &Schema{
+		Schema: &jsonschema.Schema{
+			Type:        "object",
+			Definitions: jsonschema.Definitions{},
+		},
+		parallel: map[string]reflect.Type{answer.Name: derefType(t)},
+		name:     answer.Name,
+	}
+
+	for _, tool := range tools {
+		if _, ok := s.parallel[tool.name]; ok {
+			return nil, "", fmt.Errorf("more than one tool is named %q", tool.name)
+		}
+
+		args, err := NewSchema(tool.args)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(args.Functions) != 1 {
+			return nil, "", fmt.Errorf("arguments of tool %s must expose a single schema, got %d", tool.name, len(args.Functions))
+		}
+
+		s.parallel[tool.name] = tool.args
+		s.Functions = append(s.Functions, FunctionDefinition{
+			Name:        tool.name,
+			Description: tool.description,
+			Parameters:  args.Functions[0].Parameters,
+		})
+	}
+
+	s.Functions = append(s.Functions, answer)
+
+	b, err := json.MarshalIndent(s.Functions, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+	s.String = string(b)
+
+	return s, answer.Name, nil
+}
+
+func appendDescription(description string, sentence string) string {
+	if description == "" {
+		return sentence
+	}
+	return description + " " + sentence
+}
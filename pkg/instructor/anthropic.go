@@ -9,8 +9,18 @@ type InstructorAnthropic struct {
 
 	provider   Provider
 	mode       Mode
+	streamMode Mode
+	// auto is set if the modes were picked by ModeAuto
+	auto       bool
 	maxRetries int
 	validate   bool
+
+	rateLimiter       *RateLimiter
+	cache             *ResponseCache
+	truncationRetries int
+
+	// err is why the client can't be used, e.g. an unsupported mode
+	err error
 }
 
 var _ Instructor = &InstructorAnthropic{}
@@ -22,11 +32,16 @@ func FromAnthropic(client *anthropic.Client, opts ...Options) *InstructorAnthrop
 	i := &InstructorAnthropic{
 		Client: client,
 
-		provider:   ProviderOpenAI,
-		mode:       *options.Mode,
+		provider:   ProviderAnthropic,
 		maxRetries: *options.MaxRetries,
 		validate:   *options.validate,
+
+		rateLimiter:       options.rateLimiter,
+		cache:             options.cache,
+		truncationRetries: *options.truncationRetries,
 	}
+	i.mode, i.streamMode, i.auto, i.err = resolveModes(i.provider, ProviderModes(i.provider), options.Mode)
+
 	return i
 }
 
@@ -38,9 +53,31 @@ func (i *InstructorAnthropic) Mode() string {
 	return i.mode
 }
 
+func (i *InstructorAnthropic) StreamMode() Mode {
+	return i.streamMode
+}
+
+// requestMode returns the mode of a synchronous or streaming request, which
+// with ModeAuto is the best mode the model of the request supports.
+func (i *InstructorAnthropic) requestMode(request interface{}, stream bool) Mode {
+	return modelMode(i, i.auto, ProviderModes(i.provider), request, stream)
+}
+
 func (i *InstructorAnthropic) Provider() string {
 	return i.provider
 }
 func (i *InstructorAnthropic) Validate() bool {
 	return i.validate
 }
+func (i *InstructorAnthropic) RateLimiter() *RateLimiter {
+	return i.rateLimiter
+}
+func (i *InstructorAnthropic) Cache() *ResponseCache {
+	return i.cache
+}
+func (i *InstructorAnthropic) TruncationRetries() int {
+	return i.truncationRetries
+}
+func (i *InstructorAnthropic) Err() error {
+	return i.err
+}
@@ -35,14 +35,130 @@ func (i *InstructorAnthropic) chat(ctx context.Context, request interface{}, sch
 		return "", nil, errors.New("streaming is not supported by this method; use CreateChatCompletionStream instead")
 	}
 
-	switch i.Mode() {
+	mode := i.requestMode(request, false)
+
+	switch mode {
 	case ModeToolCall:
 		return i.completionToolCall(ctx, &req, schema)
 	case ModeJSONSchema:
 		return i.completionJSONSchema(ctx, &req, schema)
 	default:
-		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", i.Mode(), i.Provider())
+		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorAnthropic) modelName(request interface{}) string {
+	req, ok := request.(anthropic.MessagesRequest)
+	if !ok {
+		return ""
+	}
+	return string(req.Model)
+}
+
+func (i *InstructorAnthropic) increaseMaxTokens(request interface{}) (interface{}, bool) {
+	req, ok := request.(anthropic.MessagesRequest)
+	if !ok || req.MaxTokens == 0 {
+		return request, false
+	}
+	req.MaxTokens *= 2
+	return req, true
+}
+
+func (i *InstructorAnthropic) reask(request interface{}, response string, feedback string) interface{} {
+	req, ok := request.(anthropic.MessagesRequest)
+	if !ok {
+		return request
+	}
+
+	messages := make([]anthropic.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages,
+		anthropic.NewAssistantTextMessage(response),
+		anthropic.NewUserTextMessage(feedback),
+	)
+
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorAnthropic) addUserMessage(request interface{}, message string) interface{} {
+	req, ok := request.(anthropic.MessagesRequest)
+	if !ok {
+		return request
 	}
+
+	messages := make([]anthropic.Message, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages...)
+
+	// roles have to alternate, so add to the tool results of a recorded reply
+	if last := len(messages) - 1; last >= 0 && messages[last].Role == anthropic.RoleUser {
+		content := make([]anthropic.MessageContent, 0, len(messages[last].Content)+1)
+		content = append(content, messages[last].Content...)
+		content = append(content, anthropic.NewTextMessageContent(message))
+		messages[last] = anthropic.Message{Role: anthropic.RoleUser, Content: content}
+	} else {
+		messages = append(messages, anthropic.NewUserTextMessage(message))
+	}
+
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorAnthropic) addReply(request interface{}, response interface{}, reply string) interface{} {
+	req, ok := request.(anthropic.MessagesRequest)
+	if !ok {
+		return request
+	}
+
+	assistant := anthropic.NewAssistantTextMessage(reply)
+	if resp, ok := response.(*anthropic.MessagesResponse); ok && len(resp.Content) > 0 {
+		assistant = anthropic.Message{Role: anthropic.RoleAssistant, Content: resp.Content}
+	}
+
+	var results []anthropic.MessageContent
+	for _, c := range assistant.Content {
+		if c.Type == anthropic.MessagesContentTypeToolUse {
+			results = append(results, anthropic.NewToolResultMessageContent(c.ID, recordedReply, false))
+		}
+	}
+
+	messages := make([]anthropic.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, assistant)
+	if len(results) > 0 {
+		messages = append(messages, anthropic.Message{Role: anthropic.RoleUser, Content: results})
+	}
+
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorAnthropic) addToolResults(request interface{}, calls []parallelCall, results []toolResult) interface{} {
+	req, ok := request.(anthropic.MessagesRequest)
+	if !ok {
+		return request
+	}
+
+	assistant := anthropic.Message{Role: anthropic.RoleAssistant}
+	user := anthropic.Message{Role: anthropic.RoleUser}
+	for idx, call := range calls {
+		assistant.Content = append(assistant.Content, anthropic.MessageContent{
+			Type: anthropic.MessagesContentTypeToolUse,
+			MessageContentToolUse: &anthropic.MessageContentToolUse{
+				ID:    call.ID,
+				Name:  call.Name,
+				Input: call.Arguments,
+			},
+		})
+		user.Content = append(user.Content, anthropic.NewToolResultMessageContent(call.ID, results[idx].content, results[idx].isError))
+	}
+
+	messages := make([]anthropic.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, assistant, user)
+
+	req.Messages = messages
+	return req
 }
 
 func (i *InstructorAnthropic) completionToolCall(ctx context.Context, request *anthropic.MessagesRequest, schema *Schema) (string, *anthropic.MessagesResponse, error) {
@@ -58,11 +174,23 @@ func (i *InstructorAnthropic) completionToolCall(ctx context.Context, request *a
 		request.Tools = append(request.Tools, t)
 	}
 
+	// force a tool call, to the only tool if there's one
+	if len(request.Tools) == 1 && schema.parallel == nil {
+		request.ToolChoice = &anthropic.ToolChoice{Type: "tool", Name: request.Tools[0].Name}
+	} else {
+		request.ToolChoice = &anthropic.ToolChoice{Type: "any"}
+	}
+
 	resp, err := i.Client.CreateMessages(ctx, *request)
 	if err != nil {
 		return "", nil, err
 	}
 
+	if err := checkAnthropicResponse(&resp); err != nil {
+		return "", nilAnthropicRespWithUsage(&resp), err
+	}
+
+	var calls []toolCall
 	for _, c := range resp.Content {
 		if c.Type != anthropic.MessagesContentTypeToolUse {
 			// Skip non tool responses
@@ -73,12 +201,15 @@ func (i *InstructorAnthropic) completionToolCall(ctx context.Context, request *a
 		if err != nil {
 			return "", nilAnthropicRespWithUsage(&resp), err
 		}
-		// TODO: handle more than 1 tool use
-		return string(toolInput), &resp, nil
+		calls = append(calls, toolCall{id: c.ID, name: c.Name, arguments: string(toolInput)})
 	}
 
-	return "", nilAnthropicRespWithUsage(&resp), errors.New("more than 1 tool response at a time is not implemented")
+	text, err := schema.joinToolCalls(calls)
+	if err != nil {
+		return "", nilAnthropicRespWithUsage(&resp), err
+	}
 
+	return text, &resp, nil
 }
 
 func (i *InstructorAnthropic) completionJSONSchema(ctx context.Context, request *anthropic.MessagesRequest, schema *Schema) (string, *anthropic.MessagesResponse, error) {
@@ -102,9 +233,32 @@ Make sure to return an instance of the JSON, not the schema itself.
 		return "", nil, err
 	}
 
-	text := resp.Content[0].Text
+	if err := checkAnthropicResponse(&resp); err != nil {
+		return "", nilAnthropicRespWithUsage(&resp), err
+	}
 
-	return *text, &resp, nil
+	for _, c := range resp.Content {
+		if c.Type == anthropic.MessagesContentTypeText && c.Text != nil {
+			return *c.Text, &resp, nil
+		}
+	}
+
+	return "", nilAnthropicRespWithUsage(&resp), errors.New("received no text content from model")
+}
+
+func checkAnthropicResponse(resp *anthropic.MessagesResponse) error {
+	switch resp.StopReason {
+	case anthropic.MessagesStopReasonMaxTokens:
+		return &TruncationError{Provider: ProviderAnthropic, Reason: string(resp.StopReason)}
+	case "refusal":
+		return &RefusalError{Provider: ProviderAnthropic, Refusal: string(resp.StopReason)}
+	}
+
+	if len(resp.Content) == 0 {
+		return errors.New("received no content from model")
+	}
+
+	return nil
 }
 
 func (i *InstructorAnthropic) emptyResponseWithUsageSum(usage *UsageSum) interface{} {
@@ -132,6 +286,9 @@ func (i *InstructorAnthropic) addUsageSumToResponse(response interface{}, usage
 	if !ok {
 		return response, fmt.Errorf("internal type error: expected *anthropic.MessagesResponse, got %T", response)
 	}
+	if resp == nil {
+		return i.emptyResponseWithUsageSum(usage), nil
+	}
 
 	resp.Usage.InputTokens += usage.InputTokens
 	resp.Usage.OutputTokens += usage.OutputTokens
@@ -141,7 +298,7 @@ func (i *InstructorAnthropic) addUsageSumToResponse(response interface{}, usage
 
 func (i *InstructorAnthropic) countUsageFromResponse(response interface{}, usage *UsageSum) *UsageSum {
 	resp, ok := response.(*anthropic.MessagesResponse)
-	if !ok {
+	if !ok || resp == nil {
 		return usage
 	}
 
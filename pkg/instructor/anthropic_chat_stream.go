@@ -2,8 +2,9 @@ package instructor
 
 import (
 	"context"
+	"fmt"
 )
 
 func (i *InstructorAnthropic) chatStream(ctx context.Context, request interface{}, schemaIn interface{}) (<-chan string, error) {
-	panic("unimplemented")
+	return nil, fmt.Errorf("streaming is not supported for %s", i.Provider())
 }
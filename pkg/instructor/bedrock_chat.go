@@ -0,0 +1,406 @@
+package instructor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+func (i *InstructorBedrock) Converse(
+	ctx context.Context,
+	request *bedrockruntime.ConverseInput,
+	response any,
+) (*bedrockruntime.ConverseOutput, error) {
+
+	resp, err := chatHandler(i, ctx, request, response)
+	if err != nil {
+		if resp == nil {
+			return &bedrockruntime.ConverseOutput{}, err
+		}
+		return nilBedrockRespWithUsage(resp.(*bedrockruntime.ConverseOutput)), err
+	}
+
+	return resp.(*bedrockruntime.ConverseOutput), nil
+}
+
+func (i *InstructorBedrock) chat(ctx context.Context, request interface{}, schemaIn interface{}) (string, interface{}, error) {
+	schema := schemaIn.(*Schema)
+	req, ok := request.(*bedrockruntime.ConverseInput)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid request type for %s client", i.Provider())
+	}
+
+	// copy so the caller's request is left untouched
+	copied := *req
+
+	mode := i.requestMode(request, false)
+
+	switch mode {
+	case ModeToolCall:
+		return i.chatToolCall(ctx, &copied, schema)
+	case ModeJSON:
+		return i.chatJSON(ctx, &copied, schema)
+	default:
+		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorBedrock) modelName(request interface{}) string {
+	switch req := request.(type) {
+	case *bedrockruntime.ConverseInput:
+		return aws.ToString(req.ModelId)
+	case *bedrockruntime.ConverseStreamInput:
+		return aws.ToString(req.ModelId)
+	}
+	return ""
+}
+
+func (i *InstructorBedrock) increaseMaxTokens(request interface{}) (interface{}, bool) {
+	req, ok := request.(*bedrockruntime.ConverseInput)
+	if !ok || req.InferenceConfig == nil || req.InferenceConfig.MaxTokens == nil {
+		return request, false
+	}
+
+	// copy so the caller's request is left untouched
+	config := *req.InferenceConfig
+	config.MaxTokens = aws.Int32(*config.MaxTokens * 2)
+
+	increased := *req
+	increased.InferenceConfig = &config
+	return &increased, true
+}
+
+func (i *InstructorBedrock) reask(request interface{}, response string, feedback string) interface{} {
+	req, ok := request.(*bedrockruntime.ConverseInput)
+	if !ok {
+		return request
+	}
+
+	messages := make([]types.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages,
+		bedrockTextMessage(types.ConversationRoleAssistant, response),
+		bedrockTextMessage(types.ConversationRoleUser, feedback),
+	)
+
+	// copy so the caller's request is left untouched
+	reasked := *req
+	reasked.Messages = messages
+	return &reasked
+}
+
+func (i *InstructorBedrock) addUserMessage(request interface{}, message string) interface{} {
+	req, ok := request.(*bedrockruntime.ConverseInput)
+	if !ok {
+		return request
+	}
+
+	messages := make([]types.Message, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages...)
+
+	// roles have to alternate, so add to the tool results of a recorded reply
+	if last := len(messages) - 1; last >= 0 && messages[last].Role == types.ConversationRoleUser {
+		content := make([]types.ContentBlock, 0, len(messages[last].Content)+1)
+		content = append(content, messages[last].Content...)
+		content = append(content, &types.ContentBlockMemberText{Value: message})
+		messages[last] = types.Message{Role: types.ConversationRoleUser, Content: content}
+	} else {
+		messages = append(messages, bedrockTextMessage(types.ConversationRoleUser, message))
+	}
+
+	// copy so the caller's request is left untouched
+	added := *req
+	added.Messages = messages
+	return &added
+}
+
+func (i *InstructorBedrock) addReply(request interface{}, response interface{}, reply string) interface{} {
+	req, ok := request.(*bedrockruntime.ConverseInput)
+	if !ok {
+		return request
+	}
+
+	assistant := bedrockTextMessage(types.ConversationRoleAssistant, reply)
+	if resp, ok := response.(*bedrockruntime.ConverseOutput); ok {
+		if output, ok := resp.Output.(*types.ConverseOutputMemberMessage); ok && len(output.Value.Content) > 0 {
+			assistant = output.Value
+		}
+	}
+
+	var results []types.ContentBlock
+	for _, block := range assistant.Content {
+		if use, ok := block.(*types.ContentBlockMemberToolUse); ok {
+			results = append(results, bedrockToolResult(aws.ToString(use.Value.ToolUseId), toolResult{content: recordedReply}))
+		}
+	}
+
+	messages := make([]types.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, assistant)
+	if len(results) > 0 {
+		messages = append(messages, types.Message{Role: types.ConversationRoleUser, Content: results})
+	}
+
+	// copy so the caller's request is left untouched
+	added := *req
+	added.Messages = messages
+	return &added
+}
+
+func (i *InstructorBedrock) addToolResults(request interface{}, calls []parallelCall, results []toolResult) interface{} {
+	req, ok := request.(*bedrockruntime.ConverseInput)
+	if !ok {
+		return request
+	}
+
+	assistant := types.Message{Role: types.ConversationRoleAssistant}
+	user := types.Message{Role: types.ConversationRoleUser}
+	for idx, call := range calls {
+		var input any
+		if err := json.Unmarshal(call.Arguments, &input); err != nil {
+			input = map[string]any{}
+		}
+		assistant.Content = append(assistant.Content, &types.ContentBlockMemberToolUse{
+			Value: types.ToolUseBlock{
+				ToolUseId: aws.String(call.ID),
+				Name:      aws.String(call.Name),
+				Input:     document.NewLazyDocument(input),
+			},
+		})
+		user.Content = append(user.Content, bedrockToolResult(call.ID, results[idx]))
+	}
+
+	messages := make([]types.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, assistant, user)
+
+	// copy so the caller's request is left untouched
+	added := *req
+	added.Messages = messages
+	return &added
+}
+
+func (i *InstructorBedrock) chatToolCall(ctx context.Context, request *bedrockruntime.ConverseInput, schema *Schema) (string, *bedrockruntime.ConverseOutput, error) {
+
+	toolConfig, err := createBedrockToolConfig(schema)
+	if err != nil {
+		return "", nil, err
+	}
+	request.ToolConfig = toolConfig
+
+	resp, err := i.Client.Converse(ctx, request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	message, err := bedrockResponseMessage(resp)
+	if err != nil {
+		return "", nilBedrockRespWithUsage(resp), err
+	}
+
+	var calls []toolCall
+	for _, block := range message.Content {
+		use, ok := block.(*types.ContentBlockMemberToolUse)
+		if !ok {
+			// Skip non tool responses
+			continue
+		}
+
+		arguments, err := use.Value.Input.MarshalSmithyDocument()
+		if err != nil {
+			return "", nilBedrockRespWithUsage(resp), err
+		}
+		calls = append(calls, toolCall{id: aws.ToString(use.Value.ToolUseId), name: aws.ToString(use.Value.Name), arguments: string(arguments)})
+	}
+
+	text, err := schema.joinToolCalls(calls)
+	if err != nil {
+		return "", nilBedrockRespWithUsage(resp), err
+	}
+
+	return text, resp, nil
+}
+
+func (i *InstructorBedrock) chatJSON(ctx context.Context, request *bedrockruntime.ConverseInput, schema *Schema) (string, *bedrockruntime.ConverseOutput, error) {
+
+	request.System = appendBedrockSystemPrompt(request.System, createBedrockJSONPrompt(schema))
+
+	resp, err := i.Client.Converse(ctx, request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	message, err := bedrockResponseMessage(resp)
+	if err != nil {
+		return "", nilBedrockRespWithUsage(resp), err
+	}
+
+	for _, block := range message.Content {
+		if text, ok := block.(*types.ContentBlockMemberText); ok {
+			return text.Value, resp, nil
+		}
+	}
+
+	return "", nilBedrockRespWithUsage(resp), errors.New("received no text content from model")
+}
+
+// createBedrockToolConfig declares the response tools and forces a call of
+// the response tool, or of any tool when there are several.
+func createBedrockToolConfig(schema *Schema) (*types.ToolConfiguration, error) {
+	config := &types.ToolConfiguration{}
+
+	for _, function := range schema.Functions {
+		// the document encoder doesn't use MarshalJSON, so pass plain values
+		b, err := json.Marshal(function.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		var parameters map[string]any
+		if err := json.Unmarshal(b, &parameters); err != nil {
+			return nil, err
+		}
+
+		spec := types.ToolSpecification{
+			Name:        aws.String(function.Name),
+			InputSchema: &types.ToolInputSchemaMemberJson{Value: document.NewLazyDocument(parameters)},
+		}
+		if function.Description != "" {
+			spec.Description = aws.String(function.Description)
+		}
+		config.Tools = append(config.Tools, &types.ToolMemberToolSpec{Value: spec})
+	}
+
+	if len(schema.Functions) == 1 && schema.parallel == nil {
+		config.ToolChoice = &types.ToolChoiceMemberTool{Value: types.SpecificToolChoice{Name: aws.String(schema.Functions[0].Name)}}
+	} else {
+		config.ToolChoice = &types.ToolChoiceMemberAny{}
+	}
+
+	return config, nil
+}
+
+func createBedrockJSONPrompt(schema *Schema) string {
+	return fmt.Sprintf(`
+Please respond with JSON in the following JSON schema:
+
+%s
+
+Make sure to return an instance of the JSON, not the schema itself
+`, schema.String)
+}
+
+func appendBedrockSystemPrompt(system []types.SystemContentBlock, prompt string) []types.SystemContentBlock {
+	blocks := make([]types.SystemContentBlock, 0, len(system)+1)
+	blocks = append(blocks, system...)
+	return append(blocks, &types.SystemContentBlockMemberText{Value: prompt})
+}
+
+func bedrockTextMessage(role types.ConversationRole, text string) types.Message {
+	return types.Message{
+		Role:    role,
+		Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: text}},
+	}
+}
+
+func bedrockToolResult(toolUseID string, result toolResult) types.ContentBlock {
+	block := types.ToolResultBlock{
+		ToolUseId: aws.String(toolUseID),
+		Content:   []types.ToolResultContentBlock{&types.ToolResultContentBlockMemberText{Value: result.content}},
+	}
+	if result.isError {
+		block.Status = types.ToolResultStatusError
+	}
+	return &types.ContentBlockMemberToolResult{Value: block}
+}
+
+// bedrockResponseMessage returns the message of a response, or a typed error
+// if the model ran out of tokens or was filtered.
+func bedrockResponseMessage(resp *bedrockruntime.ConverseOutput) (*types.Message, error) {
+	if err := checkBedrockStopReason(resp.StopReason); err != nil {
+		return nil, err
+	}
+
+	output, ok := resp.Output.(*types.ConverseOutputMemberMessage)
+	if !ok || len(output.Value.Content) == 0 {
+		return nil, errors.New("received no content from model")
+	}
+
+	return &output.Value, nil
+}
+
+func checkBedrockStopReason(reason types.StopReason) error {
+	switch reason {
+	case types.StopReasonMaxTokens:
+		return &TruncationError{Provider: ProviderBedrock, Reason: string(reason)}
+	case types.StopReasonContentFiltered, types.StopReasonGuardrailIntervened:
+		return &ContentFilterError{Provider: ProviderBedrock, Reason: string(reason)}
+	}
+	return nil
+}
+
+func (i *InstructorBedrock) emptyResponseWithUsageSum(usage *UsageSum) interface{} {
+	return &bedrockruntime.ConverseOutput{
+		Usage: &types.TokenUsage{
+			InputTokens:  aws.Int32(int32(usage.InputTokens)),
+			OutputTokens: aws.Int32(int32(usage.OutputTokens)),
+			TotalTokens:  aws.Int32(int32(usage.TotalTokens)),
+		},
+	}
+}
+
+func (i *InstructorBedrock) emptyResponseWithResponseUsage(response interface{}) interface{} {
+	resp, ok := response.(*bedrockruntime.ConverseOutput)
+	if !ok || resp == nil {
+		return nil
+	}
+
+	return nilBedrockRespWithUsage(resp)
+}
+
+func (i *InstructorBedrock) addUsageSumToResponse(response interface{}, usage *UsageSum) (interface{}, error) {
+	resp, ok := response.(*bedrockruntime.ConverseOutput)
+	if !ok {
+		return response, fmt.Errorf("internal type error: expected *bedrockruntime.ConverseOutput, got %T", response)
+	}
+	if resp == nil {
+		return i.emptyResponseWithUsageSum(usage), nil
+	}
+
+	if resp.Usage == nil {
+		resp.Usage = &types.TokenUsage{}
+	}
+	resp.Usage.InputTokens = aws.Int32(aws.ToInt32(resp.Usage.InputTokens) + int32(usage.InputTokens))
+	resp.Usage.OutputTokens = aws.Int32(aws.ToInt32(resp.Usage.OutputTokens) + int32(usage.OutputTokens))
+	resp.Usage.TotalTokens = aws.Int32(aws.ToInt32(resp.Usage.TotalTokens) + int32(usage.TotalTokens))
+
+	return response, nil
+}
+
+func (i *InstructorBedrock) countUsageFromResponse(response interface{}, usage *UsageSum) *UsageSum {
+	resp, ok := response.(*bedrockruntime.ConverseOutput)
+	if !ok || resp == nil || resp.Usage == nil {
+		return usage
+	}
+
+	usage.InputTokens += int(aws.ToInt32(resp.Usage.InputTokens))
+	usage.OutputTokens += int(aws.ToInt32(resp.Usage.OutputTokens))
+	usage.TotalTokens += int(aws.ToInt32(resp.Usage.TotalTokens))
+
+	return usage
+}
+
+func nilBedrockRespWithUsage(resp *bedrockruntime.ConverseOutput) *bedrockruntime.ConverseOutput {
+	if resp == nil {
+		return nil
+	}
+
+	return &bedrockruntime.ConverseOutput{
+		Usage: resp.Usage,
+	}
+}
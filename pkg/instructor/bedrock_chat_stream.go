@@ -0,0 +1,106 @@
+package instructor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+func (i *InstructorBedrock) ConverseStream(
+	ctx context.Context,
+	request *bedrockruntime.ConverseStreamInput,
+	responseType any,
+) (<-chan any, error) {
+
+	stream, err := chatStreamHandler(i, ctx, request, responseType)
+	if err != nil {
+		return nil, err
+	}
+
+	return stream, err
+}
+
+func (i *InstructorBedrock) chatStream(ctx context.Context, request interface{}, schemaIn interface{}) (<-chan string, error) {
+	schema := schemaIn.(*Schema)
+	req, ok := request.(*bedrockruntime.ConverseStreamInput)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type for %s client", i.Provider())
+	}
+
+	// copy so the caller's request is left untouched
+	copied := *req
+
+	mode := i.requestMode(request, true)
+
+	switch mode {
+	case ModeToolCall:
+		return i.chatToolCallStream(ctx, &copied, schema)
+	case ModeJSON:
+		return i.chatJSONStream(ctx, &copied, schema)
+	default:
+		return nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorBedrock) chatToolCallStream(ctx context.Context, request *bedrockruntime.ConverseStreamInput, schema *Schema) (<-chan string, error) {
+	toolConfig, err := createBedrockToolConfig(schema)
+	if err != nil {
+		return nil, err
+	}
+	request.ToolConfig = toolConfig
+	return i.createStream(ctx, request)
+}
+
+func (i *InstructorBedrock) chatJSONStream(ctx context.Context, request *bedrockruntime.ConverseStreamInput, schema *Schema) (<-chan string, error) {
+	request.System = appendBedrockSystemPrompt(request.System, createBedrockJSONPrompt(schema))
+	return i.createStream(ctx, request)
+}
+
+// createStream sends the text and tool input deltas of the stream.
+func (i *InstructorBedrock) createStream(ctx context.Context, request *bedrockruntime.ConverseStreamInput) (<-chan string, error) {
+	resp, err := i.Client.ConverseStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := resp.GetStream()
+
+	ch := make(chan string)
+
+	go func() {
+		defer stream.Close()
+		defer close(ch)
+		for event := range stream.Events() {
+			if metadata, ok := event.(*types.ConverseStreamOutputMemberMetadata); ok && metadata.Value.Usage != nil {
+				recordStreamUsage(ctx, i.countUsageFromResponse(&bedrockruntime.ConverseOutput{Usage: metadata.Value.Usage}, &UsageSum{}))
+			}
+
+			delta, ok := event.(*types.ConverseStreamOutputMemberContentBlockDelta)
+			if !ok {
+				continue
+			}
+
+			var text string
+			switch d := delta.Value.Delta.(type) {
+			case *types.ContentBlockDeltaMemberText:
+				text = d.Value
+			case *types.ContentBlockDeltaMemberToolUse:
+				if d.Value.Input != nil {
+					text = *d.Value.Input
+				}
+			}
+			if text == "" {
+				continue
+			}
+
+			select {
+			case ch <- text:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
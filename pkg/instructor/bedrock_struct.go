@@ -0,0 +1,80 @@
+package instructor
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+)
+
+type InstructorBedrock struct {
+	*bedrockruntime.Client
+
+	provider   Provider
+	mode       Mode
+	streamMode Mode
+	// auto is set if the modes were picked by ModeAuto
+	auto       bool
+	maxRetries int
+	validate   bool
+
+	rateLimiter       *RateLimiter
+	cache             *ResponseCache
+	truncationRetries int
+
+	// err is why the client can't be used, e.g. an unsupported mode
+	err error
+}
+
+var _ Instructor = &InstructorBedrock{}
+
+func FromBedrock(client *bedrockruntime.Client, opts ...Options) *InstructorBedrock {
+
+	options := mergeOptions(opts...)
+
+	i := &InstructorBedrock{
+		Client: client,
+
+		provider:   ProviderBedrock,
+		maxRetries: *options.MaxRetries,
+		validate:   *options.validate,
+
+		rateLimiter:       options.rateLimiter,
+		cache:             options.cache,
+		truncationRetries: *options.truncationRetries,
+	}
+	i.mode, i.streamMode, i.auto, i.err = resolveModes(i.provider, ProviderModes(i.provider), options.Mode)
+
+	return i
+}
+
+func (i *InstructorBedrock) Provider() Provider {
+	return i.provider
+}
+func (i *InstructorBedrock) Mode() Mode {
+	return i.mode
+}
+func (i *InstructorBedrock) StreamMode() Mode {
+	return i.streamMode
+}
+
+// requestMode returns the mode of a synchronous or streaming request, which
+// with ModeAuto is the best mode the model of the request supports.
+func (i *InstructorBedrock) requestMode(request interface{}, stream bool) Mode {
+	return modelMode(i, i.auto, ProviderModes(i.provider), request, stream)
+}
+func (i *InstructorBedrock) MaxRetries() int {
+	return i.maxRetries
+}
+func (i *InstructorBedrock) Validate() bool {
+	return i.validate
+}
+func (i *InstructorBedrock) RateLimiter() *RateLimiter {
+	return i.rateLimiter
+}
+func (i *InstructorBedrock) Cache() *ResponseCache {
+	return i.cache
+}
+func (i *InstructorBedrock) TruncationRetries() int {
+	return i.truncationRetries
+}
+func (i *InstructorBedrock) Err() error {
+	return i.err
+}
@@ -0,0 +1,333 @@
+package instructor
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache is a key/value backend for validated responses. Implement it to plug in
+// shared stores such as Redis or memcached. A ttl of 0 means no expiry.
+type Cache interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// ResponseCache caches the validated JSON of synchronous extractions keyed by
+// provider, endpoint, model, request, response schema and citation source.
+// Requests without a model name aren't cached.
+type ResponseCache struct {
+	Backend Cache
+	TTL     time.Duration
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	// Errors counts failed backend calls, cached responses that couldn't be
+	// decoded and requests that couldn't be hashed into a key, which are sent
+	// uncached.
+	Errors int64
+}
+
+func NewResponseCache(backend Cache, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		Backend: backend,
+		TTL:     ttl,
+	}
+}
+
+func (c *ResponseCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+type cacheBypassKey struct{}
+
+type cacheBypass int
+
+const (
+	cacheBypassNone cacheBypass = iota
+	cacheBypassRead
+	cacheBypassAll
+)
+
+// SkipCache returns a context for which the response cache is neither read nor
+// written.
+func SkipCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, cacheBypassAll)
+}
+
+// RefreshCache returns a context for which the cache lookup is skipped but the
+// fresh response is still stored.
+func RefreshCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, cacheBypassRead)
+}
+
+func cacheBypassFromContext(ctx context.Context) cacheBypass {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(cacheBypass)
+	return bypass
+}
+
+// get unmarshals a cached response into response and reports whether it was found.
+func (c *ResponseCache) get(ctx context.Context, key string, response any) bool {
+	if c == nil || cacheBypassFromContext(ctx) != cacheBypassNone {
+		return false
+	}
+
+	value, ok, err := c.Backend.Get(ctx, key)
+	if err != nil {
+		c.errors.Add(1)
+	}
+	if err != nil || !ok {
+		c.misses.Add(1)
+		return false
+	}
+
+	if err := json.Unmarshal(value, response); err != nil {
+		c.errors.Add(1)
+		c.misses.Add(1)
+		return false
+	}
+
+	c.hits.Add(1)
+	return true
+}
+
+func (c *ResponseCache) set(ctx context.Context, key string, response any) {
+	if c == nil || cacheBypassFromContext(ctx) == cacheBypassAll {
+		return
+	}
+
+	value, err := json.Marshal(response)
+	if err == nil {
+		err = c.Backend.Set(ctx, key, value, c.TTL)
+	}
+	if err != nil {
+		c.errors.Add(1)
+	}
+}
+
+// cacheKey returns the key of the response to request, or an empty key for
+// requests without a model name, which aren't cached.
+func cacheKey(ctx context.Context, i Instructor, request interface{}, schema interface{}) (string, error) {
+	model := i.modelName(request)
+	if model == "" {
+		// responses of different models must not be mixed up
+		return "", nil
+	}
+
+	req, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+
+	var sch []byte
+	switch s := schema.(type) {
+	case *Schema:
+		sch = []byte(s.String)
+	default:
+		sch, err = json.Marshal(s)
+		if err != nil {
+			return "", err
+		}
+	}
+	schemaHash := sha256.Sum256(sch)
+
+	// responses are verified against the citation source, which is part of
+	// the request as much as the messages are
+	var citations []byte
+	if source, ok := citationSourceFromContext(ctx); ok {
+		sourceHash := sha256.Sum256([]byte(source.text))
+		citations = append(sourceHash[:], fmt.Sprint(source.drop)...)
+	}
+
+	h := sha256.New()
+	for _, part := range [][]byte{
+		[]byte(i.Provider()),
+		[]byte(cacheScope(i)),
+		[]byte(model),
+		[]byte(i.requestMode(request, false)),
+		req,
+		schemaHash[:],
+		citations,
+	} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cacheScope returns what keeps the cached responses of i apart from those of
+// other clients of the same provider, e.g. the endpoint of an Azure OpenAI
+// resource.
+func cacheScope(i Instructor) string {
+	if scoped, ok := i.(interface{ cacheScope() string }); ok {
+		return scoped.cacheScope()
+	}
+	return ""
+}
+
+// MemoryCache is an in-process LRU Cache holding at most Size entries
+// (unbounded if Size is 0).
+type MemoryCache struct {
+	Size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+var _ Cache = &MemoryCache{}
+
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{
+		Size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+
+	return entry.value, true, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+
+	for c.Size > 0 && c.order.Len() > c.Size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+
+	return nil
+}
+
+// FileCache stores each entry as a JSON file in Dir.
+type FileCache struct {
+	Dir string
+}
+
+type fileCacheEntry struct {
+	ExpiresAt time.Time       `json:"expires_at,omitempty"`
+	Value     json.RawMessage `json:"value"`
+}
+
+var _ Cache = &FileCache{}
+
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{Dir: dir}, nil
+}
+
+func (c *FileCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
+
+func (c *FileCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var entry fileCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, err
+	}
+
+	if !entry.ExpiresAt.IsZero() && time.Now().After(entry.ExpiresAt) {
+		_ = os.Remove(c.path(key))
+		return nil, false, nil
+	}
+
+	return entry.Value, true, nil
+}
+
+func (c *FileCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := fileCacheEntry{Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// write to a temp file first so concurrent readers never see partial entries
+	tmp, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.path(key))
+}
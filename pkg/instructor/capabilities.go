@@ -0,0 +1,218 @@
+package instructor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ModeCapabilities are the modes supported for synchronous and streaming
+// calls, in the order ModeAuto prefers them.
+type ModeCapabilities struct {
+	Modes       []Mode
+	StreamModes []Mode
+}
+
+// Supports reports whether mode is supported for synchronous calls, or for
+// streaming calls if stream is set.
+func (c ModeCapabilities) Supports(mode Mode, stream bool) bool {
+	if stream {
+		return slices.Contains(c.StreamModes, mode)
+	}
+	return slices.Contains(c.Modes, mode)
+}
+
+// Best returns the mode ModeAuto picks for synchronous calls, or for
+// streaming calls if stream is set, and false if there is none.
+func (c ModeCapabilities) Best(stream bool) (Mode, bool) {
+	modes := c.Modes
+	if stream {
+		modes = c.StreamModes
+	}
+	if len(modes) == 0 {
+		return "", false
+	}
+	return modes[0], true
+}
+
+// filter returns the capabilities with only the modes keep returns true for.
+func (c ModeCapabilities) filter(keep func(Mode) bool) ModeCapabilities {
+	discard := func(mode Mode) bool { return !keep(mode) }
+	return ModeCapabilities{
+		Modes:       slices.DeleteFunc(slices.Clone(c.Modes), discard),
+		StreamModes: slices.DeleteFunc(slices.Clone(c.StreamModes), discard),
+	}
+}
+
+// intersect returns the modes of c that other supports too, in the order of c.
+func (c ModeCapabilities) intersect(other ModeCapabilities) ModeCapabilities {
+	return ModeCapabilities{
+		Modes:       slices.DeleteFunc(slices.Clone(c.Modes), func(mode Mode) bool { return !other.Supports(mode, false) }),
+		StreamModes: slices.DeleteFunc(slices.Clone(c.StreamModes), func(mode Mode) bool { return !other.Supports(mode, true) }),
+	}
+}
+
+// providerCapabilities are the modes each provider supports with any of its
+// models. Modes every model supports come first, so ModeAuto works whatever
+// the model.
+var providerCapabilities = map[Provider]ModeCapabilities{
+	ProviderOpenAI: {
+		Modes:       []Mode{ModeToolCall, ModeToolCallStrict, ModeStructuredOutputs, ModeJSONStrict, ModeJSON, ModeJSONSchema},
+		StreamModes: []Mode{ModeToolCall, ModeToolCallStrict, ModeStructuredOutputs, ModeJSON, ModeJSONSchema},
+	},
+	ProviderAnthropic: {
+		Modes: []Mode{ModeToolCall, ModeJSONSchema},
+	},
+	ProviderCohere: {
+		Modes:       []Mode{ModeToolCall, ModeJSON},
+		StreamModes: []Mode{ModeJSON},
+	},
+	ProviderGoogleAI: {
+		Modes:       []Mode{ModeToolCall, ModeJSON},
+		StreamModes: []Mode{ModeJSON},
+	},
+	ProviderOllama: {
+		Modes:       []Mode{ModeJSONSchema, ModeJSON},
+		StreamModes: []Mode{ModeJSONSchema, ModeJSON},
+	},
+	ProviderMistral: {
+		Modes:       []Mode{ModeToolCall, ModeJSON},
+		StreamModes: []Mode{ModeToolCall, ModeJSON},
+	},
+	ProviderBedrock: {
+		Modes:       []Mode{ModeToolCall, ModeJSON},
+		StreamModes: []Mode{ModeToolCall, ModeJSON},
+	},
+}
+
+type modelCapabilities struct {
+	// family of the models, matching the model of that name and its versions
+	// and variants, like gpt-4-turbo and gpt-4-0613 for gpt-4 or claude-2.1
+	// for claude-2
+	family       string
+	capabilities ModeCapabilities
+}
+
+// knownModels are the models known to support fewer modes than their
+// provider. The most specific family matching a model applies.
+var knownModels = map[Provider][]modelCapabilities{
+	ProviderOpenAI: {
+		{"gpt-4o-2024-05-13", ModeCapabilities{
+			Modes:       []Mode{ModeToolCall, ModeJSON, ModeJSONSchema},
+			StreamModes: []Mode{ModeToolCall, ModeJSON, ModeJSONSchema},
+		}},
+		{"gpt-4", ModeCapabilities{
+			Modes:       []Mode{ModeToolCall, ModeJSON, ModeJSONSchema},
+			StreamModes: []Mode{ModeToolCall, ModeJSON, ModeJSONSchema},
+		}},
+		{"gpt-3.5-turbo", ModeCapabilities{
+			Modes:       []Mode{ModeToolCall, ModeJSON, ModeJSONSchema},
+			StreamModes: []Mode{ModeToolCall, ModeJSON, ModeJSONSchema},
+		}},
+		{"o1-preview", ModeCapabilities{
+			Modes: []Mode{ModeJSONSchema},
+		}},
+		{"o1-mini", ModeCapabilities{
+			Modes: []Mode{ModeJSONSchema},
+		}},
+	},
+	ProviderAnthropic: {
+		{"claude-2", ModeCapabilities{Modes: []Mode{ModeJSONSchema}}},
+		{"claude-instant", ModeCapabilities{Modes: []Mode{ModeJSONSchema}}},
+	},
+	ProviderMistral: {
+		{"open-mistral-7b", ModeCapabilities{
+			Modes:       []Mode{ModeJSON},
+			StreamModes: []Mode{ModeJSON},
+		}},
+		{"open-mixtral-8x7b", ModeCapabilities{
+			Modes:       []Mode{ModeJSON},
+			StreamModes: []Mode{ModeJSON},
+		}},
+	},
+}
+
+// ProviderModes returns the modes supported by provider with any of its
+// models, or no modes for an unknown provider.
+func ProviderModes(provider Provider) ModeCapabilities {
+	return providerCapabilities[provider]
+}
+
+// ModelModes returns the modes supported by a model of provider, which are
+// those of the provider for models not known to support fewer.
+func ModelModes(provider Provider, model string) ModeCapabilities {
+	var best *modelCapabilities
+	for i, known := range knownModels[provider] {
+		if model != known.family && !strings.HasPrefix(model, known.family+"-") && !strings.HasPrefix(model, known.family+".") {
+			continue
+		}
+		if best == nil || len(known.family) > len(best.family) {
+			best = &knownModels[provider][i]
+		}
+	}
+
+	if best == nil {
+		return ProviderModes(provider)
+	}
+	return best.capabilities
+}
+
+// resolveModes returns the modes of a client for synchronous and streaming
+// calls, and whether they were picked by ModeAuto. ModeAuto picks the best of
+// capabilities for each, and an unset mode is ModeDefault where supported and
+// ModeAuto otherwise. It returns an error if the client supports mode for
+// neither synchronous nor streaming calls.
+func resolveModes(provider Provider, capabilities ModeCapabilities, mode *Mode) (Mode, Mode, bool, error) {
+	if mode == nil {
+		mode = toPtr(ModeAuto)
+		if capabilities.Supports(ModeDefault, false) {
+			mode = toPtr(ModeDefault)
+		}
+	}
+
+	if *mode != ModeAuto {
+		if !capabilities.Supports(*mode, false) && !capabilities.Supports(*mode, true) {
+			return *mode, *mode, false, fmt.Errorf("instructor: mode '%s' is not supported for %s", *mode, provider)
+		}
+		return *mode, *mode, false, nil
+	}
+
+	sync, ok := capabilities.Best(false)
+	if !ok {
+		return *mode, *mode, true, fmt.Errorf("instructor: no mode is supported for %s", provider)
+	}
+
+	stream, ok := capabilities.Best(true)
+	if !ok {
+		// streaming calls fail with the synchronous mode as they would with any
+		stream = sync
+	}
+
+	return sync, stream, true, nil
+}
+
+// modelMode returns the mode of a synchronous or streaming request of i. With
+// ModeAuto it is the best of capabilities, the modes of the client, that the
+// model of the request supports, falling back to the mode of the client for
+// models supporting none of them.
+func modelMode(i Instructor, auto bool, capabilities ModeCapabilities, request interface{}, stream bool) Mode {
+	mode := i.Mode()
+	if stream {
+		mode = i.StreamMode()
+	}
+	if !auto {
+		return mode
+	}
+
+	model := ModelModes(i.Provider(), i.modelName(request)).intersect(capabilities)
+
+	best, ok := model.Best(stream)
+	if !ok && stream {
+		// streaming calls fail with the synchronous mode as they would with any
+		best, ok = model.Best(false)
+	}
+	if !ok {
+		return mode
+	}
+	return best
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/binarycraft007/instructor-go/pkg/instructor/googleai"
 )
 
 type UsageSum struct {
@@ -17,38 +17,77 @@ type UsageSum struct {
 }
 
 func chatHandler(i Instructor, ctx context.Context, request interface{}, response any) (interface{}, error) {
-
-	var err error
-	var schema interface{}
+	if err := i.Err(); err != nil {
+		return nil, err
+	}
 
 	t := reflect.TypeOf(response)
 
-	if i.Provider() == ProviderGoogleAI {
-		schema, err = googleai.GenerateSchemaFromType(t)
+	schema, err := schemas.forProvider(i.Provider(), t)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := schemas.envelope(t)
+
+	var key string
+	if c := i.Cache(); c != nil {
+		key, err = cacheKey(ctx, i, request, schema)
 		if err != nil {
-			return nil, err
+			// the request is sent uncached, but the failure shows in the stats
+			c.errors.Add(1)
 		}
-	} else {
-		schema, err = NewSchema(t)
-		if err != nil {
-			return nil, err
+		if key != "" && c.get(ctx, key, response) {
+			// spans aren't cached, so locate the quotes again
+			source, ok := citationSourceFromContext(ctx)
+			if !ok || verifyCitations(response, source) == nil {
+				return i.emptyResponseWithUsageSum(&UsageSum{}), nil
+			}
+			// a cached response that no longer verifies is fetched again
+			reflect.ValueOf(response).Elem().SetZero()
 		}
 	}
 
 	// keep a running total of usage
 	usage := &UsageSum{}
 
+	truncationRetries := i.TruncationRetries()
+
+	// the reason the last attempt failed, returned once out of retries
+	var lastErr error
+
 	for attempt := 0; attempt <= i.MaxRetries(); attempt++ {
 
+		reservation, err := i.RateLimiter().wait(ctx, estimateTokens(request, schema))
+		if err != nil {
+			return i.emptyResponseWithUsageSum(usage), err
+		}
+
 		text, resp, err := i.chat(ctx, request, schema)
+
+		var truncated *TruncationError
+		if errors.As(err, &truncated) && truncationRetries > 0 {
+			if increased, ok := i.increaseMaxTokens(request); ok {
+				i.RateLimiter().record(reservation, i.countUsageFromResponse(resp, &UsageSum{}))
+				i.countUsageFromResponse(resp, usage)
+
+				request = increased
+				truncationRetries--
+				attempt--
+				continue
+			}
+		}
+
 		if err != nil {
 			// no retry on non-marshalling/validation errors
 			return i.emptyResponseWithResponseUsage(resp), err
 		}
 
+		i.RateLimiter().record(reservation, i.countUsageFromResponse(resp, &UsageSum{}))
+
 		text = extractJSON(&text)
 
-		err = json.Unmarshal([]byte(text), &response)
+		target, err := unmarshalResponse(text, response, envelope)
 		if err != nil {
 			// TODO:
 			// add more sophisticated retry logic (send back json and parse error for model to fix).
@@ -57,25 +96,79 @@ func chatHandler(i Instructor, ctx context.Context, request interface{}, respons
 			// or attempt to fix the error with the last generated JSON
 
 			i.countUsageFromResponse(resp, usage)
+			lastErr = err
 			continue
 		}
 
+		if err := validateEnums(target); err != nil {
+			i.countUsageFromResponse(resp, usage)
+			lastErr = err
+			request = i.reask(request, text, fmt.Sprintf("Your response was invalid: %s. Respond again with a corrected response.", err))
+			continue
+		}
+
+		if source, ok := citationSourceFromContext(ctx); ok {
+			if err := verifyCitations(target, source); err != nil {
+				i.countUsageFromResponse(resp, usage)
+				lastErr = err
+				request = i.reask(request, text, fmt.Sprintf("Your response was invalid: %s. Respond again with quotes copied verbatim from the source.", err))
+				continue
+			}
+		}
+
 		if i.Validate() {
 			validate = validator.New()
 			// Validate the response structure against the defined model using the validator
-			err = validate.Struct(response)
+			err = validate.Struct(target)
 
 			if err != nil {
-				// TODO:
-				// add more sophisticated retry logic (send back validator error and parse error for model to fix).
-
 				i.countUsageFromResponse(resp, usage)
+				lastErr = err
+				request = i.reask(request, text, fmt.Sprintf("Your response was invalid: %s. Respond again with a corrected response.", err))
 				continue
 			}
 		}
 
+		if envelope != nil {
+			unwrapEnvelope(target, response)
+		}
+
+		if key != "" {
+			i.Cache().set(ctx, key, response)
+		}
+
 		return i.addUsageSumToResponse(resp, usage)
 	}
 
-	return i.emptyResponseWithUsageSum(usage), errors.New("hit max retry attempts")
+	return i.emptyResponseWithUsageSum(usage), retriesExhausted(lastErr)
+}
+
+// retriesExhausted returns the error for running out of retries, wrapping why
+// the last attempt failed if known.
+func retriesExhausted(lastErr error) error {
+	if lastErr == nil {
+		return errors.New("hit max retry attempts")
+	}
+	return fmt.Errorf("hit max retry attempts: %w", lastErr)
+}
+
+// unmarshalResponse decodes text into response, or into a new envelope if the
+// response type needs one, and returns what was decoded into.
+func unmarshalResponse(text string, response any, envelope reflect.Type) (any, error) {
+	if envelope == nil {
+		return response, decodeJSON([]byte(text), response)
+	}
+
+	target := reflect.New(envelope)
+	result := target.Elem().Field(0).Addr().Interface()
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(text), &fields); err == nil {
+		if raw, ok := fields[envelopeField]; ok {
+			return target.Interface(), decodeJSON(raw, result)
+		}
+	}
+
+	// models sometimes answer with the bare value instead of the envelope
+	return target.Interface(), decodeJSON([]byte(text), result)
 }
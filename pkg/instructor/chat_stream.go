@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
-	"github.com/binarycraft007/instructor-go/pkg/instructor/googleai"
 )
 
 type StreamWrapper[T any] struct {
@@ -17,35 +16,22 @@ type StreamWrapper[T any] struct {
 const WRAPPER_END = `"items": [`
 
 func chatStreamHandler(i Instructor, ctx context.Context, request interface{}, response any) (<-chan interface{}, error) {
-	var err error
-	var schema interface{}
-
+	if err := i.Err(); err != nil {
+		return nil, err
+	}
 	responseType := reflect.TypeOf(response)
 
-	if i.Provider() == ProviderGoogleAI {
-		// Create a slice type of the response type
-		sliceType := reflect.SliceOf(responseType)
-		schema, err = googleai.GenerateSchemaFromType(sliceType)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		streamWrapperType := reflect.StructOf([]reflect.StructField{
-			{
-				Name:      "Items",
-				Type:      reflect.SliceOf(responseType),
-				Tag:       `json:"items"`,
-				Anonymous: false,
-			},
-		})
-
-		schema, err = NewSchema(streamWrapperType)
-		if err != nil {
-			return nil, err
-		}
+	schema, err := schemas.streamForProvider(i.Provider(), responseType)
+	if err != nil {
+		return nil, err
 	}
 
-	ch, err := i.chatStream(ctx, request, schema)
+	reservation, err := i.RateLimiter().wait(ctx, estimateTokens(request, schema))
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := i.chatStream(i.RateLimiter().withStreamUsage(ctx, reservation), request, schema)
 	if err != nil {
 		return nil, err
 	}
@@ -55,12 +41,14 @@ func chatStreamHandler(i Instructor, ctx context.Context, request interface{}, r
 		validate = validator.New()
 	}
 
-	parsedChan := parseStream(ctx, ch, shouldValidate, responseType)
+	parsedChan := parseStream(ctx, ch, shouldValidate, responseType, schemas.streamItemType(responseType))
 
 	return parsedChan, nil
 }
 
-func parseStream(ctx context.Context, ch <-chan string, shouldValidate bool, responseType reflect.Type) <-chan interface{} {
+// parseStream decodes each streamed element into itemType, which is either
+// responseType or its envelope, and sends a pointer to a responseType value.
+func parseStream(ctx context.Context, ch <-chan string, shouldValidate bool, responseType reflect.Type, itemType reflect.Type) <-chan interface{} {
 
 	parsedChan := make(chan any)
 
@@ -77,7 +65,7 @@ func parseStream(ctx context.Context, ch <-chan string, shouldValidate bool, res
 			case text, ok := <-ch:
 				if !ok {
 					// Stream closed
-					processRemainingBuffer(buffer, parsedChan, shouldValidate, responseType)
+					processRemainingBuffer(buffer, parsedChan, shouldValidate, responseType, itemType)
 					return
 				}
 
@@ -88,7 +76,7 @@ func parseStream(ctx context.Context, ch <-chan string, shouldValidate bool, res
 					inArray = startArray(buffer)
 				}
 
-				processBuffer(buffer, parsedChan, shouldValidate, responseType)
+				processBuffer(buffer, parsedChan, shouldValidate, responseType, itemType)
 			}
 		}
 	}()
@@ -112,7 +100,7 @@ func startArray(buffer *strings.Builder) bool {
 	return true
 }
 
-func processBuffer(buffer *strings.Builder, parsedChan chan<- interface{}, shouldValidate bool, responseType reflect.Type) {
+func processBuffer(buffer *strings.Builder, parsedChan chan<- interface{}, shouldValidate bool, responseType reflect.Type, itemType reflect.Type) {
 
 	data := buffer.String()
 
@@ -121,12 +109,21 @@ func processBuffer(buffer *strings.Builder, parsedChan chan<- interface{}, shoul
 	decoder := json.NewDecoder(strings.NewReader(data))
 
 	for decoder.More() {
-		instance := reflect.New(responseType).Interface()
-		err := decoder.Decode(instance)
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			break
+		}
+
+		instance := reflect.New(itemType).Interface()
+		err := decodeJSON(raw, instance)
 		if err != nil {
 			break
 		}
 
+		if err := validateEnums(instance); err != nil {
+			break
+		}
+
 		if shouldValidate {
 			// Validate the instance
 			err = validate.Struct(instance)
@@ -135,6 +132,12 @@ func processBuffer(buffer *strings.Builder, parsedChan chan<- interface{}, shoul
 			}
 		}
 
+		if itemType != responseType {
+			unwrapped := reflect.New(responseType)
+			unwrapEnvelope(instance, unwrapped.Interface())
+			instance = unwrapped.Interface()
+		}
+
 		parsedChan <- instance
 
 		buffer.Reset()
@@ -142,7 +145,7 @@ func processBuffer(buffer *strings.Builder, parsedChan chan<- interface{}, shoul
 	}
 }
 
-func processRemainingBuffer(buffer *strings.Builder, parsedChan chan<- interface{}, shouldValidate bool, responseType reflect.Type) {
+func processRemainingBuffer(buffer *strings.Builder, parsedChan chan<- interface{}, shouldValidate bool, responseType reflect.Type, itemType reflect.Type) {
 
 	data := buffer.String()
 
@@ -152,6 +155,6 @@ func processRemainingBuffer(buffer *strings.Builder, parsedChan chan<- interface
 		data = data[:idx]
 	}
 
-	processBuffer(buffer, parsedChan, shouldValidate, responseType)
+	processBuffer(buffer, parsedChan, shouldValidate, responseType, itemType)
 
 }
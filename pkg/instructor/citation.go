@@ -0,0 +1,357 @@
+package instructor
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Cited marks a response field as requiring citations: the model has to back
+// the value with verbatim quotes from the source text. When the source is set
+// on the request context with WithCitationSource, every quote is located in
+// the source after parsing and its position recorded in Spans. Facts with a
+// quote that can't be found are sent back to the model with the CitationError
+// to correct, or dropped with DropUncitedFacts.
+//
+//	type Finding struct {
+//		Risks []instructor.Cited[string] `json:"risks"`
+//	}
+type Cited[T any] struct {
+	Value  T        `json:"value"`
+	Quotes []string `json:"quotes" jsonschema:"description=Verbatim quotes from the source text that support the value,minItems=1"`
+
+	// Spans holds the location of each quote in the source, in order.
+	Spans []Span `json:"-"`
+}
+
+// Span locates a quote in the source text by byte offsets, Source[Start:End].
+// Similarity is 1 for an exact match and lower for fuzzy ones.
+type Span struct {
+	Start      int
+	End        int
+	Similarity float64
+}
+
+// MinCitationSimilarity is the lowest similarity for a quote to count as found
+// when it doesn't appear verbatim, e.g. because the model fixed a typo or
+// changed punctuation.
+var MinCitationSimilarity = 0.85
+
+type citationSourceKey struct{}
+
+type citationSource struct {
+	text string
+	drop bool
+}
+
+// WithCitationSource returns a context carrying the source text that the
+// quotes of Cited fields are verified against.
+func WithCitationSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, citationSourceKey{}, &citationSource{text: source})
+}
+
+// DropUncitedFacts returns a context for which facts whose quotes can't be
+// found in the source are removed from the response instead of retried:
+// deleted from slices and maps, and zeroed elsewhere.
+func DropUncitedFacts(ctx context.Context) context.Context {
+	source, _ := ctx.Value(citationSourceKey{}).(*citationSource)
+	if source == nil {
+		source = &citationSource{}
+	}
+	return context.WithValue(ctx, citationSourceKey{}, &citationSource{text: source.text, drop: true})
+}
+
+func citationSourceFromContext(ctx context.Context) (*citationSource, bool) {
+	source, ok := ctx.Value(citationSourceKey{}).(*citationSource)
+	return source, ok && source.text != ""
+}
+
+// CitationError is returned when a quote of a Cited field isn't in the source.
+type CitationError struct {
+	Path  string
+	Quote string
+}
+
+func (e *CitationError) Error() string {
+	if e.Quote == "" {
+		return fmt.Sprintf("%s: no quotes from the source were given", e.Path)
+	}
+	return fmt.Sprintf("%s: quote %q was not found in the source", e.Path, e.Quote)
+}
+
+// cited is implemented by *Cited[T].
+type cited interface {
+	verifyCitations(source *sourceIndex, path string) error
+}
+
+var citedType = reflect.TypeOf((*cited)(nil)).Elem()
+
+func (c *Cited[T]) verifyCitations(source *sourceIndex, path string) error {
+	c.Spans = nil
+
+	if len(c.Quotes) == 0 {
+		return &CitationError{Path: path}
+	}
+
+	for _, quote := range c.Quotes {
+		span, ok := source.find(quote)
+		if !ok {
+			return &CitationError{Path: path, Quote: quote}
+		}
+		c.Spans = append(c.Spans, span)
+	}
+
+	return nil
+}
+
+// verifyCitations locates the quotes of every Cited value in v. With drop set,
+// unverified facts are removed instead of reported.
+func verifyCitations(v any, source *citationSource) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil
+	}
+
+	index := newSourceIndex(source.text)
+	_, err := walkCitations(rv, index, "$", source.drop)
+	return err
+}
+
+// walkCitations returns false if v is a fact to drop.
+func walkCitations(v reflect.Value, index *sourceIndex, path string, drop bool) (bool, error) {
+	if v.CanAddr() && v.Addr().Type().Implements(citedType) {
+		err := v.Addr().Interface().(cited).verifyCitations(index, path)
+		if err != nil && drop {
+			return false, nil
+		}
+		return true, err
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return true, nil
+		}
+		return walkCitations(v.Elem(), index, path, drop)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return true, nil
+		}
+		// interface values aren't addressable, so work on a copy
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		keep, err := walkCitations(elem, index, path, drop)
+		if keep && v.CanSet() {
+			v.Set(elem)
+		}
+		return keep, err
+
+	case reflect.Slice:
+		kept := 0
+		for i := 0; i < v.Len(); i++ {
+			keep, err := walkCitations(v.Index(i), index, fmt.Sprintf("%s[%d]", path, i), drop)
+			if err != nil {
+				return true, err
+			}
+			if keep {
+				v.Index(kept).Set(v.Index(i))
+				kept++
+			}
+		}
+		if kept < v.Len() && v.CanSet() {
+			v.SetLen(kept)
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			keep, err := walkCitations(v.Index(i), index, fmt.Sprintf("%s[%d]", path, i), drop)
+			if err != nil {
+				return true, err
+			}
+			if !keep {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			}
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+
+			keep, err := walkCitations(elem, index, fmt.Sprintf("%s[%v]", path, iter.Key().Interface()), drop)
+			if err != nil {
+				return true, err
+			}
+			if keep {
+				v.SetMapIndex(iter.Key(), elem)
+			} else {
+				v.SetMapIndex(iter.Key(), reflect.Value{})
+			}
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			keep, err := walkCitations(v.Field(i), index, path+"."+jsonFieldName(field), drop)
+			if err != nil {
+				return true, err
+			}
+			if !keep {
+				v.Field(i).Set(reflect.Zero(field.Type))
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// sourceIndex finds quotes in a source text, first verbatim, then ignoring
+// case and whitespace, then fuzzily.
+type sourceIndex struct {
+	text string
+
+	normalized []rune
+	offsets    []int // byte offset in text of each normalized rune, plus len(text)
+}
+
+func newSourceIndex(text string) *sourceIndex {
+	normalized, offsets := normalizeText(text)
+	return &sourceIndex{
+		text:       text,
+		normalized: normalized,
+		offsets:    offsets,
+	}
+}
+
+// normalizeText lowercases text and collapses whitespace, returning the byte
+// offset in text of every rune kept.
+func normalizeText(text string) ([]rune, []int) {
+	normalized := make([]rune, 0, len(text))
+	offsets := make([]int, 0, len(text)+1)
+
+	space := false
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			space = len(normalized) > 0
+			continue
+		}
+		if space {
+			normalized = append(normalized, ' ')
+			offsets = append(offsets, i-1)
+			space = false
+		}
+		normalized = append(normalized, unicode.ToLower(r))
+		offsets = append(offsets, i)
+	}
+
+	return normalized, append(offsets, len(text))
+}
+
+func (s *sourceIndex) find(quote string) (Span, bool) {
+	quote = strings.TrimSpace(quote)
+	if quote == "" {
+		return Span{}, false
+	}
+
+	if start := strings.Index(s.text, quote); start != -1 {
+		return Span{Start: start, End: start + len(quote), Similarity: 1}, true
+	}
+
+	q, _ := normalizeText(quote)
+
+	if start := indexRunes(s.normalized, q); start != -1 {
+		return s.span(start, start+len(q), 1), true
+	}
+
+	start, end, distance := approximateMatch(s.normalized, q)
+	similarity := 1 - float64(distance)/float64(len(q))
+	if similarity < MinCitationSimilarity {
+		return Span{}, false
+	}
+
+	return s.span(start, end, similarity), true
+}
+
+// span maps normalized rune positions back to byte offsets in the text.
+func (s *sourceIndex) span(start, end int, similarity float64) Span {
+	last := s.offsets[end-1]
+	_, size := utf8.DecodeRuneInString(s.text[last:])
+	return Span{Start: s.offsets[start], End: last + size, Similarity: similarity}
+}
+
+func indexRunes(text, sub []rune) int {
+	for i := 0; i+len(sub) <= len(text); i++ {
+		match := true
+		for j := range sub {
+			if text[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// approximateMatch returns the substring of text with the smallest edit
+// distance to pattern (Sellers' algorithm).
+func approximateMatch(text, pattern []rune) (start, end, distance int) {
+	m := len(pattern)
+
+	// column j holds the distance of pattern[:j] to the best substring ending at
+	// the current position of text, and starts the position it started at
+	prev := make([]int, m+1)
+	cur := make([]int, m+1)
+	prevStart := make([]int, m+1)
+	curStart := make([]int, m+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	distance = m
+	start, end = 0, 0
+
+	for i := 1; i <= len(text); i++ {
+		cur[0], curStart[0] = 0, i
+
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if text[i-1] == pattern[j-1] {
+				cost = 0
+			}
+
+			cur[j], curStart[j] = prev[j-1]+cost, prevStart[j-1]
+			if prev[j]+1 < cur[j] {
+				cur[j], curStart[j] = prev[j]+1, prevStart[j]
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j], curStart[j] = cur[j-1]+1, curStart[j-1]
+			}
+		}
+
+		if cur[m] < distance {
+			distance, start, end = cur[m], curStart[m], i
+		}
+
+		prev, cur = cur, prev
+		prevStart, curStart = curStart, prevStart
+	}
+
+	if end <= start {
+		return 0, 0, m
+	}
+
+	return start, end, distance
+}
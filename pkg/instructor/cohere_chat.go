@@ -2,10 +2,12 @@ package instructor
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	cohere "github.com/cohere-ai/cohere-go/v2"
 	option "github.com/cohere-ai/cohere-go/v2/option"
+	"github.com/invopop/jsonschema"
 )
 
 func (i *InstructorCohere) Chat(
@@ -33,31 +35,137 @@ func (i *InstructorCohere) chat(ctx context.Context, request interface{}, schema
 		return "", nil, fmt.Errorf("invalid request type for %s client", i.Provider())
 	}
 
-	switch i.Mode() {
+	// copy so the caller's request is left untouched, e.g. the preamble isn't
+	// extended again on every retry
+	copied := *req
+
+	mode := i.requestMode(request, false)
+
+	switch mode {
 	case ModeToolCall:
-		return i.chatToolCall(ctx, req, schema)
+		return i.chatToolCall(ctx, &copied, schema)
 	case ModeJSON:
-		return i.chatJSON(ctx, req, schema)
+		return i.chatJSON(ctx, &copied, schema)
 	default:
-		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", i.Mode(), i.Provider())
+		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorCohere) modelName(request interface{}) string {
+	req, ok := request.(*cohere.ChatRequest)
+	if !ok || req.Model == nil {
+		return ""
+	}
+	return *req.Model
+}
+
+func (i *InstructorCohere) increaseMaxTokens(request interface{}) (interface{}, bool) {
+	req, ok := request.(*cohere.ChatRequest)
+	if !ok || req.MaxTokens == nil {
+		return request, false
+	}
+	// copy so the caller's request is left untouched
+	increased := *req
+	increased.MaxTokens = toPtr(*req.MaxTokens * 2)
+	return &increased, true
+}
+
+func (i *InstructorCohere) reask(request interface{}, response string, feedback string) interface{} {
+	req, ok := request.(*cohere.ChatRequest)
+	if !ok {
+		return request
+	}
+
+	history := make([]*cohere.Message, 0, len(req.ChatHistory)+2)
+	history = append(history, req.ChatHistory...)
+	history = append(history,
+		&cohere.Message{Role: "USER", User: &cohere.ChatMessage{Message: req.Message}},
+		&cohere.Message{Role: "CHATBOT", Chatbot: &cohere.ChatMessage{Message: response}},
+	)
+
+	// copy so the caller's request is left untouched
+	reasked := *req
+	reasked.ChatHistory = history
+	reasked.Message = feedback
+	return &reasked
+}
+
+func (i *InstructorCohere) addUserMessage(request interface{}, message string) interface{} {
+	req, ok := request.(*cohere.ChatRequest)
+	if !ok {
+		return request
 	}
+
+	// copy so the caller's request is left untouched
+	added := *req
+	if req.Message != "" {
+		added.ChatHistory = make([]*cohere.Message, 0, len(req.ChatHistory)+1)
+		added.ChatHistory = append(added.ChatHistory, req.ChatHistory...)
+		added.ChatHistory = append(added.ChatHistory, &cohere.Message{Role: "USER", User: &cohere.ChatMessage{Message: req.Message}})
+	}
+	added.Message = message
+	return &added
+}
+
+func (i *InstructorCohere) addReply(request interface{}, response interface{}, reply string) interface{} {
+	req, ok := request.(*cohere.ChatRequest)
+	if !ok {
+		return request
+	}
+
+	// tool calls would need tool results in the history, so the reply is kept
+	// as text
+	if resp, ok := response.(*cohere.NonStreamedChatResponse); ok && resp.Text != "" && len(resp.ToolCalls) == 0 {
+		reply = resp.Text
+	}
+
+	history := make([]*cohere.Message, 0, len(req.ChatHistory)+2)
+	history = append(history, req.ChatHistory...)
+	history = append(history,
+		&cohere.Message{Role: "USER", User: &cohere.ChatMessage{Message: req.Message}},
+		&cohere.Message{Role: "CHATBOT", Chatbot: &cohere.ChatMessage{Message: reply}},
+	)
+
+	// copy so the caller's request is left untouched
+	added := *req
+	added.ChatHistory = history
+	added.Message = ""
+	return &added
+}
+
+// addToolResults is not supported yet, RunAgent rejects Cohere clients.
+func (i *InstructorCohere) addToolResults(request interface{}, calls []parallelCall, results []toolResult) interface{} {
+	return request
 }
 
 func (i *InstructorCohere) chatToolCall(ctx context.Context, request *cohere.ChatRequest, schema *Schema) (string, *cohere.NonStreamedChatResponse, error) {
 
-	request.Tools = []*cohere.Tool{createCohereTools(schema)}
+	request.Tools = createCohereTools(schema)
 
 	resp, err := i.Client.Chat(ctx, request)
 	if err != nil {
 		return "", nil, err
 	}
 
-	_ = resp
+	if err := checkCohereResponse(resp); err != nil {
+		return "", nilCohereRespWithUsage(resp), err
+	}
 
-	// TODO: implement
+	calls := make([]toolCall, 0, len(resp.ToolCalls))
+	for _, call := range resp.ToolCalls {
+		arguments, err := json.Marshal(call.Parameters)
+		if err != nil {
+			return "", nilCohereRespWithUsage(resp), err
+		}
+		calls = append(calls, toolCall{name: call.Name, arguments: string(arguments)})
+	}
 
-	panic("tool call not implemented Cohere")
+	text, err := schema.joinToolCalls(calls)
+	if err != nil {
+		return "", nilCohereRespWithUsage(resp), err
+	}
 
+	return text, resp, nil
 }
 
 func (i *InstructorCohere) chatJSON(ctx context.Context, request *cohere.ChatRequest, schema *Schema) (string, *cohere.NonStreamedChatResponse, error) {
@@ -69,9 +177,32 @@ func (i *InstructorCohere) chatJSON(ctx context.Context, request *cohere.ChatReq
 		return "", nil, err
 	}
 
+	if err := checkCohereResponse(resp); err != nil {
+		return "", nilCohereRespWithUsage(resp), err
+	}
+
 	return resp.Text, resp, nil
 }
 
+func checkCohereResponse(resp *cohere.NonStreamedChatResponse) error {
+	if resp.FinishReason == nil {
+		return nil
+	}
+
+	reason := *resp.FinishReason
+
+	switch reason {
+	case cohere.FinishReasonMaxTokens, cohere.FinishReasonErrorLimit:
+		return &TruncationError{Provider: ProviderCohere, Reason: string(reason)}
+	case cohere.FinishReasonErrorToxic:
+		return &ContentFilterError{Provider: ProviderCohere, Reason: string(reason)}
+	case cohere.FinishReasonError:
+		return fmt.Errorf("%s generation failed (%s)", ProviderCohere, reason)
+	}
+
+	return nil
+}
+
 func (i *InstructorCohere) addOrConcatJSONSystemPrompt(request *cohere.ChatRequest, schema *Schema) {
 
 	schemaPrompt := fmt.Sprintf("```json!Please respond with JSON in the following JSON schema - make sure to return an instance of the JSON, not the schema itself: %s ", schema.String)
@@ -110,42 +241,114 @@ func (i *InstructorCohere) addUsageSumToResponse(response interface{}, usage *Us
 	if !ok {
 		return response, fmt.Errorf("internal type error: expected *cohere.NonStreamedChatResponse, got %T", response)
 	}
+	if resp == nil {
+		return i.emptyResponseWithUsageSum(usage), nil
+	}
 
-	*resp.Meta.Tokens.InputTokens += float64(usage.InputTokens)
-	*resp.Meta.Tokens.OutputTokens += float64(usage.OutputTokens)
+	if resp.Meta == nil {
+		resp.Meta = &cohere.ApiMeta{}
+	}
+	if resp.Meta.Tokens == nil {
+		resp.Meta.Tokens = &cohere.ApiMetaTokens{}
+	}
+	tokens := resp.Meta.Tokens
+	tokens.InputTokens = toPtr(tokenCount(tokens.InputTokens) + float64(usage.InputTokens))
+	tokens.OutputTokens = toPtr(tokenCount(tokens.OutputTokens) + float64(usage.OutputTokens))
 
 	return response, nil
 }
 
 func (i *InstructorCohere) countUsageFromResponse(response interface{}, usage *UsageSum) *UsageSum {
 	resp, ok := response.(*cohere.NonStreamedChatResponse)
-	if !ok {
+	if !ok || resp == nil || resp.Meta == nil || resp.Meta.Tokens == nil {
 		return usage
 	}
 
-	usage.InputTokens += int(*resp.Meta.Tokens.InputTokens)
-	usage.OutputTokens += int(*resp.Meta.Tokens.OutputTokens)
+	usage.InputTokens += int(tokenCount(resp.Meta.Tokens.InputTokens))
+	usage.OutputTokens += int(tokenCount(resp.Meta.Tokens.OutputTokens))
 
 	return usage
 }
 
-func createCohereTools(schema *Schema) *cohere.Tool {
-
-	tool := &cohere.Tool{
-		Name:                 "functions",
-		Description:          schema.Schema.Description,
-		ParameterDefinitions: make(map[string]*cohere.ToolParameterDefinitionsValue),
+// tokenCount returns a token count of a Cohere response, which is nil when
+// missing.
+func tokenCount(count *float64) float64 {
+	if count == nil {
+		return 0
 	}
+	return *count
+}
+
+// createCohereTools exposes every function as a tool. Cohere only describes
+// top level parameters by a Python type name, so nested objects and arrays
+// carry their JSON schema in the description.
+func createCohereTools(schema *Schema) []*cohere.Tool {
+
+	tools := make([]*cohere.Tool, 0, len(schema.Functions))
 
 	for _, function := range schema.Functions {
-		parameterDefinition := &cohere.ToolParameterDefinitionsValue{
-			Description: toPtr(function.Description),
-			Type:        function.Parameters.Type,
+		tool := &cohere.Tool{
+			Name:                 function.Name,
+			Description:          function.Description,
+			ParameterDefinitions: make(map[string]*cohere.ToolParameterDefinitionsValue),
 		}
-		tool.ParameterDefinitions[function.Name] = parameterDefinition
+
+		required := make(map[string]bool, len(function.Parameters.Required))
+		for _, name := range function.Parameters.Required {
+			required[name] = true
+		}
+
+		if function.Parameters.Properties != nil {
+			for pair := function.Parameters.Properties.Oldest(); pair != nil; pair = pair.Next() {
+				tool.ParameterDefinitions[pair.Key] = &cohere.ToolParameterDefinitionsValue{
+					Description: toPtr(cohereParameterDescription(pair.Value, function.Parameters.Definitions)),
+					Type:        cohereParameterType(pair.Value),
+					Required:    toPtr(required[pair.Key]),
+				}
+			}
+		}
+
+		tools = append(tools, tool)
+	}
+
+	return tools
+}
+
+func cohereParameterType(s *jsonschema.Schema) string {
+	switch s.Type {
+	case "string":
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "list"
+	default:
+		return "dict"
 	}
+}
+
+func cohereParameterDescription(s *jsonschema.Schema, defs jsonschema.Definitions) string {
+	switch s.Type {
+	case "string", "integer", "number", "boolean":
+		return s.Description
+	}
+
+	parameter := *s
+	parameter.Definitions = referencedDefinitions(s, defs)
 
-	return tool
+	b, err := json.Marshal(&parameter)
+	if err != nil {
+		return s.Description
+	}
+
+	if s.Description == "" {
+		return "JSON schema: " + string(b)
+	}
+	return s.Description + " JSON schema: " + string(b)
 }
 
 func nilCohereRespWithUsage(resp *cohere.NonStreamedChatResponse) *cohere.NonStreamedChatResponse {
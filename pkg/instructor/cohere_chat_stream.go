@@ -32,11 +32,17 @@ func (i *InstructorCohere) chatStream(ctx context.Context, request interface{},
 		return nil, fmt.Errorf("invalid request type for %s client", i.Provider())
 	}
 
-	switch i.Mode() {
+	// copy so the caller's request is left untouched, e.g. the preamble isn't
+	// extended again on every retry
+	copied := *req
+
+	mode := i.requestMode(request, true)
+
+	switch mode {
 	case ModeJSON:
-		return i.chatJSONStream(ctx, req, schema)
+		return i.chatJSONStream(ctx, &copied, schema)
 	default:
-		return nil, fmt.Errorf("mode '%s' is not supported for %s", i.Mode(), i.Provider())
+		return nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
 	}
 }
 
@@ -79,6 +85,9 @@ func (i *InstructorCohere) createStream(ctx context.Context, request *cohere.Cha
 			case "stream-start":
 				continue
 			case "stream-end":
+				if message.StreamEnd != nil {
+					recordStreamUsage(ctx, i.countUsageFromResponse(message.StreamEnd.Response, &UsageSum{}))
+				}
 				return
 			case "text-generation":
 				ch <- message.TextGeneration.Text
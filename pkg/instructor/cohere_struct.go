@@ -9,8 +9,18 @@ type InstructorCohere struct {
 
 	provider   Provider
 	mode       Mode
+	streamMode Mode
+	// auto is set if the modes were picked by ModeAuto
+	auto       bool
 	maxRetries int
 	validate   bool
+
+	rateLimiter       *RateLimiter
+	cache             *ResponseCache
+	truncationRetries int
+
+	// err is why the client can't be used, e.g. an unsupported mode
+	err error
 }
 
 var _ Instructor = &InstructorCohere{}
@@ -23,9 +33,15 @@ func FromCohere(client *cohere.Client, opts ...Options) *InstructorCohere {
 		Client: client,
 
 		provider:   ProviderCohere,
-		mode:       *options.Mode,
 		maxRetries: *options.MaxRetries,
+		validate:   *options.validate,
+
+		rateLimiter:       options.rateLimiter,
+		cache:             options.cache,
+		truncationRetries: *options.truncationRetries,
 	}
+	i.mode, i.streamMode, i.auto, i.err = resolveModes(i.provider, ProviderModes(i.provider), options.Mode)
+
 	return i
 }
 
@@ -37,9 +53,31 @@ func (i *InstructorCohere) Mode() string {
 	return i.mode
 }
 
+func (i *InstructorCohere) StreamMode() Mode {
+	return i.streamMode
+}
+
+// requestMode returns the mode of a synchronous or streaming request, which
+// with ModeAuto is the best mode the model of the request supports.
+func (i *InstructorCohere) requestMode(request interface{}, stream bool) Mode {
+	return modelMode(i, i.auto, ProviderModes(i.provider), request, stream)
+}
+
 func (i *InstructorCohere) MaxRetries() int {
 	return i.maxRetries
 }
 func (i *InstructorCohere) Validate() bool {
 	return i.validate
 }
+func (i *InstructorCohere) RateLimiter() *RateLimiter {
+	return i.rateLimiter
+}
+func (i *InstructorCohere) Cache() *ResponseCache {
+	return i.cache
+}
+func (i *InstructorCohere) TruncationRetries() int {
+	return i.truncationRetries
+}
+func (i *InstructorCohere) Err() error {
+	return i.err
+}
@@ -0,0 +1,364 @@
+package instructor
+
+// The conformance suite runs against the unexported methods of every Instructor
+// implementation, so unlike the other tests it lives in the package itself.
+// Add a provider to conformanceCases when adding an implementation.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	cohere "github.com/cohere-ai/cohere-go/v2"
+	cohereclient "github.com/cohere-ai/cohere-go/v2/client"
+	"github.com/google/generative-ai-go/genai"
+	anthropic "github.com/liushuangls/go-anthropic/v2"
+	openai "github.com/sashabaranov/go-openai"
+	"google.golang.org/api/option"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor/googleai"
+	"github.com/binarycraft007/instructor-go/pkg/instructor/mistral"
+	"github.com/binarycraft007/instructor-go/pkg/instructor/ollama"
+)
+
+var allModes = []Mode{
+	ModeToolCall,
+	ModeToolCallStrict,
+	ModeJSON,
+	ModeJSONStrict,
+	ModeJSONSchema,
+	ModeMarkdownJSON,
+	ModeStructuredOutputs,
+	ModeGrammar,
+}
+
+type conformancePerson struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+type conformanceCase struct {
+	provider Provider
+	// new returns a client for the server at url, which fails every request
+	new func(t *testing.T, url string, opts ...Options) Instructor
+	// request and streamRequest return requests the client accepts
+	request       func(i Instructor) interface{}
+	streamRequest func(i Instructor) interface{}
+}
+
+var conformanceCases = []conformanceCase{
+	{
+		provider: ProviderOpenAI,
+		new: func(t *testing.T, url string, opts ...Options) Instructor {
+			config := openai.DefaultConfig("key")
+			config.BaseURL = url + "/v1"
+			return FromOpenAI(openai.NewClientWithConfig(config), opts...)
+		},
+		request: func(Instructor) interface{} {
+			return openai.ChatCompletionRequest{Model: openai.GPT4o}
+		},
+		streamRequest: func(Instructor) interface{} {
+			return openai.ChatCompletionRequest{Model: openai.GPT4o, Stream: true}
+		},
+	},
+	{
+		provider: ProviderAnthropic,
+		new: func(t *testing.T, url string, opts ...Options) Instructor {
+			return FromAnthropic(anthropic.NewClient("key", anthropic.WithBaseURL(url)), opts...)
+		},
+		request: func(Instructor) interface{} {
+			return anthropic.MessagesRequest{Model: anthropic.ModelClaude3Haiku20240307, MaxTokens: 100}
+		},
+		streamRequest: func(Instructor) interface{} {
+			return anthropic.MessagesRequest{Model: anthropic.ModelClaude3Haiku20240307, MaxTokens: 100, Stream: true}
+		},
+	},
+	{
+		provider: ProviderCohere,
+		new: func(t *testing.T, url string, opts ...Options) Instructor {
+			return FromCohere(cohereclient.NewClient(cohereclient.WithBaseURL(url)), opts...)
+		},
+		request: func(Instructor) interface{} {
+			return &cohere.ChatRequest{Message: "hi"}
+		},
+		streamRequest: func(Instructor) interface{} {
+			return &cohere.ChatStreamRequest{Message: "hi"}
+		},
+	},
+	{
+		provider: ProviderGoogleAI,
+		new: func(t *testing.T, url string, opts ...Options) Instructor {
+			client, err := genai.NewClient(context.Background(), option.WithAPIKey("key"), option.WithEndpoint(url))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { client.Close() })
+			return FromGoogleAI(client, opts...)
+		},
+		request: func(i Instructor) interface{} {
+			model := i.(*InstructorGoogleAI).Client.GenerativeModel("gemini-1.5-flash")
+			return &googleai.ChatRequest{Model: model, Session: model.StartChat(), Parts: []genai.Part{genai.Text("hi")}}
+		},
+		streamRequest: func(i Instructor) interface{} {
+			model := i.(*InstructorGoogleAI).Client.GenerativeModel("gemini-1.5-flash")
+			return &googleai.ChatRequest{Model: model, Session: model.StartChat(), Parts: []genai.Part{genai.Text("hi")}}
+		},
+	},
+	{
+		provider: ProviderOllama,
+		new: func(t *testing.T, url string, opts ...Options) Instructor {
+			return FromOllama(ollama.NewClient(ollama.WithBaseURL(url)), opts...)
+		},
+		request: func(Instructor) interface{} {
+			return &ollama.ChatRequest{Model: "llama3.1"}
+		},
+		streamRequest: func(Instructor) interface{} {
+			return &ollama.ChatRequest{Model: "llama3.1"}
+		},
+	},
+	{
+		provider: ProviderMistral,
+		new: func(t *testing.T, url string, opts ...Options) Instructor {
+			return FromMistral(mistral.NewClient("key", mistral.WithBaseURL(url)), opts...)
+		},
+		request: func(Instructor) interface{} {
+			return mistral.ChatCompletionRequest{Model: "mistral-large-latest"}
+		},
+		streamRequest: func(Instructor) interface{} {
+			return mistral.ChatCompletionRequest{Model: "mistral-large-latest", Stream: true}
+		},
+	},
+	{
+		provider: ProviderBedrock,
+		new: func(t *testing.T, url string, opts ...Options) Instructor {
+			return FromBedrock(bedrockruntime.New(bedrockruntime.Options{
+				Region:       "us-east-1",
+				BaseEndpoint: aws.String(url),
+				Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+					return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+				}),
+			}), opts...)
+		},
+		request: func(Instructor) interface{} {
+			return &bedrockruntime.ConverseInput{ModelId: aws.String("anthropic.claude-3-haiku"), Messages: []types.Message{}}
+		},
+		streamRequest: func(Instructor) interface{} {
+			return &bedrockruntime.ConverseStreamInput{ModelId: aws.String("anthropic.claude-3-haiku"), Messages: []types.Message{}}
+		},
+	},
+}
+
+func TestConformance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": {"message": "bad request"}, "message": "bad request"}`)
+	}))
+	defer srv.Close()
+
+	for _, tc := range conformanceCases {
+		t.Run(tc.provider, func(t *testing.T) {
+			t.Run("Provider", func(t *testing.T) {
+				testProviderIdentity(t, tc, srv.URL)
+			})
+			t.Run("Modes", func(t *testing.T) {
+				testModes(t, tc, srv.URL)
+			})
+			t.Run("Usage", func(t *testing.T) {
+				testNilUsage(t, tc.new(t, srv.URL))
+			})
+			t.Run("StreamClosing", func(t *testing.T) {
+				testStreamClosing(t, tc, srv.URL)
+			})
+		})
+	}
+}
+
+func testProviderIdentity(t *testing.T, tc conformanceCase, url string) {
+	i := tc.new(t, url)
+
+	if err := i.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+	if i.Provider() != tc.provider {
+		t.Errorf("Provider() = %s", i.Provider())
+	}
+	if len(ProviderModes(i.Provider()).Modes) == 0 {
+		t.Errorf("no modes registered")
+	}
+	if i.MaxRetries() != DefaultMaxRetries || i.Validate() != DefaultValidator {
+		t.Errorf("default options not applied: %d retries, validate %t", i.MaxRetries(), i.Validate())
+	}
+
+	i = tc.new(t, url, WithValidation(), WithMaxRetries(7))
+	if !i.Validate() || i.MaxRetries() != 7 {
+		t.Errorf("options not applied: %d retries, validate %t", i.MaxRetries(), i.Validate())
+	}
+}
+
+// testModes checks the registry against the modes the client accepts, both at
+// construction and on requests.
+func testModes(t *testing.T, tc conformanceCase, url string) {
+	capabilities := ProviderModes(tc.provider)
+	typ := reflect.TypeOf(conformancePerson{})
+
+	for _, mode := range allModes {
+		if !capabilities.Supports(mode, false) && !capabilities.Supports(mode, true) {
+			i := tc.new(t, url, WithMode(mode))
+			if i.Err() == nil {
+				t.Errorf("%s: unsupported mode accepted at construction", mode)
+				continue
+			}
+			var response conformancePerson
+			if _, err := chatHandler(i, context.Background(), tc.request(i), &response); err != i.Err() {
+				t.Errorf("%s: request failed with %v, want %v", mode, err, i.Err())
+			}
+			continue
+		}
+
+		i := tc.new(t, url, WithMode(mode))
+		if err := i.Err(); err != nil {
+			t.Errorf("%s: %v", mode, err)
+		}
+		if i.Mode() != mode || i.StreamMode() != mode {
+			t.Errorf("%s: Mode() = %s, StreamMode() = %s", mode, i.Mode(), i.StreamMode())
+		}
+
+		schema, err := schemas.forProvider(i.Provider(), typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = i.chat(context.Background(), tc.request(i), schema)
+		if supported := !isModeError(err); supported != capabilities.Supports(mode, false) {
+			t.Errorf("%s: chat supports mode = %t, registry says %t (%v)", mode, supported, !supported, err)
+		}
+
+		schema, err = schemas.streamForProvider(i.Provider(), typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch, err := i.chatStream(context.Background(), tc.streamRequest(i), schema)
+		if ch != nil {
+			drain(t, ch)
+		}
+		if capabilities.Supports(mode, true) {
+			if isModeError(err) {
+				t.Errorf("%s: chatStream rejected a supported mode: %v", mode, err)
+			}
+		} else if err == nil {
+			t.Errorf("%s: chatStream accepted an unsupported mode", mode)
+		}
+	}
+
+	i := tc.new(t, url, WithMode(ModeAuto))
+	if !capabilities.Supports(i.Mode(), false) {
+		t.Errorf("ModeAuto picked unsupported %s", i.Mode())
+	}
+	if len(capabilities.StreamModes) > 0 && !capabilities.Supports(i.StreamMode(), true) {
+		t.Errorf("ModeAuto picked unsupported %s for streams", i.StreamMode())
+	}
+}
+
+// testNilUsage checks the usage methods don't panic on missing responses or
+// usage, and keep the counts they're given.
+func testNilUsage(t *testing.T, i Instructor) {
+	usage := &UsageSum{InputTokens: 3, OutputTokens: 5, TotalTokens: 8}
+
+	empty := i.emptyResponseWithUsageSum(usage)
+	if empty == nil {
+		t.Fatal("emptyResponseWithUsageSum returned nil")
+	}
+	if got := i.countUsageFromResponse(empty, &UsageSum{}); !sameUsage(got, usage) {
+		t.Errorf("usage of emptyResponseWithUsageSum = %+v", got)
+	}
+
+	typ := reflect.TypeOf(empty)
+	typedNil := reflect.Zero(typ).Interface()
+	newZero := func() interface{} { return reflect.New(typ.Elem()).Interface() }
+
+	if got := i.emptyResponseWithResponseUsage(nil); got != nil {
+		t.Errorf("emptyResponseWithResponseUsage(nil) = %v", got)
+	}
+	if got := i.emptyResponseWithResponseUsage(typedNil); got != nil {
+		t.Errorf("emptyResponseWithResponseUsage(%T(nil)) = %v", typedNil, got)
+	}
+
+	for _, response := range []interface{}{nil, typedNil, newZero()} {
+		if got := i.countUsageFromResponse(response, &UsageSum{}); !sameUsage(got, &UsageSum{}) {
+			t.Errorf("countUsageFromResponse(%#v) = %+v", response, got)
+		}
+	}
+
+	if _, err := i.addUsageSumToResponse(nil, usage); err == nil {
+		t.Errorf("addUsageSumToResponse(nil) returned no error")
+	}
+	for _, response := range []interface{}{typedNil, newZero()} {
+		got, err := i.addUsageSumToResponse(response, usage)
+		if err != nil {
+			t.Errorf("addUsageSumToResponse(%#v): %v", response, err)
+			continue
+		}
+		if sum := i.countUsageFromResponse(got, &UsageSum{}); !sameUsage(sum, usage) {
+			t.Errorf("addUsageSumToResponse(%#v) has usage %+v", response, sum)
+		}
+	}
+
+	got, err := i.addUsageSumToResponse(i.emptyResponseWithUsageSum(usage), usage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := i.countUsageFromResponse(got, &UsageSum{}); !sameUsage(sum, &UsageSum{InputTokens: 6, OutputTokens: 10}) {
+		t.Errorf("summed usage = %+v", sum)
+	}
+}
+
+// testStreamClosing checks streams are closed when their request fails.
+func testStreamClosing(t *testing.T, tc conformanceCase, url string) {
+	i := tc.new(t, url, WithMode(ModeAuto))
+	if len(ProviderModes(tc.provider).StreamModes) == 0 {
+		if _, err := chatStreamHandler(i, context.Background(), tc.streamRequest(i), &conformancePerson{}); err == nil {
+			t.Errorf("stream accepted without streaming support")
+		}
+		return
+	}
+
+	ch, err := chatStreamHandler(i, context.Background(), tc.streamRequest(i), &conformancePerson{})
+	if err != nil {
+		return
+	}
+	drain(t, ch)
+}
+
+func drain[T any](t *testing.T, ch <-chan T) {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("stream not closed")
+		}
+	}
+}
+
+func isModeError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "is not supported for")
+}
+
+// sameUsage compares input and output tokens, as not every provider counts
+// total tokens.
+func sameUsage(got, want *UsageSum) bool {
+	return got.InputTokens == want.InputTokens && got.OutputTokens == want.OutputTokens
+}
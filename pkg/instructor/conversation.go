@@ -0,0 +1,61 @@
+package instructor
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Conversation keeps the history of a multi-turn chat, asking for a structured
+// reply on every turn. The replies are recorded in the history the way the
+// provider expects them: as the tool calls the model made, answered with a
+// tool result, or as plain text.
+//
+//	conversation := instructor.NewConversation(client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+//	user, _, err := instructor.Ask[User](ctx, conversation, "Extract the user: ...")
+//	order, _, err := instructor.Ask[Order](ctx, conversation, "Now extract their latest order.")
+//
+// The schema prompt of JSON modes is added per request only, so it appears
+// once no matter how many turns there are. A Conversation must not be used
+// concurrently.
+type Conversation struct {
+	i       Instructor
+	request interface{}
+}
+
+// NewConversation starts a conversation from request, the provider request as
+// passed to the client, holding the model, settings and any earlier messages.
+func NewConversation(i Instructor, request interface{}) *Conversation {
+	return &Conversation{i: i, request: request}
+}
+
+// Request returns the provider request holding the history so far.
+func (c *Conversation) Request() interface{} {
+	return c.request
+}
+
+// Ask sends message as the next user turn and returns the reply of type T with
+// the provider response. On error the history is left as it was, so the turn
+// can be asked again.
+func Ask[T any](ctx context.Context, c *Conversation, message string) (T, interface{}, error) {
+	var reply T
+
+	request := c.i.addUserMessage(c.request, message)
+
+	resp, err := chatHandler(c.i, ctx, request, &reply)
+	if err != nil {
+		return reply, resp, err
+	}
+
+	text, err := json.Marshal(reply)
+	if err != nil {
+		return reply, resp, err
+	}
+
+	c.request = c.i.addReply(request, resp, string(text))
+
+	return reply, resp, nil
+}
+
+// recordedReply answers the tool calls of a structured reply kept in the
+// history, as providers require every tool call to have a result.
+const recordedReply = "Recorded."
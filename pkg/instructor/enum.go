@@ -0,0 +1,205 @@
+package instructor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Enumer can be implemented by named string or number types to declare their
+// allowed values in one place:
+//
+//	type Label string
+//
+//	func (Label) Enum() []any { return []any{LabelBilling, LabelTechIssue} }
+//
+// The values become the `enum` of the type in every generated schema, and a
+// response holding any other value is rejected, with or without
+// WithValidation, and sent back to the model with the EnumError to correct.
+// Once out of retries, the last EnumError is returned wrapped.
+type Enumer interface {
+	Enum() []any
+}
+
+var enumerType = reflect.TypeOf((*Enumer)(nil)).Elem()
+
+// enumValues returns the allowed values of t, if t (or *t) implements Enumer.
+// Pointers and interfaces are never Enumer types themselves: a *Label has the
+// Enum method of Label, but its values are checked through the element.
+func enumValues(t reflect.Type) ([]any, bool) {
+	switch {
+	case t.Kind() == reflect.Ptr, t.Kind() == reflect.Interface:
+		return nil, false
+	case t.Implements(enumerType):
+		return reflect.Zero(t).Interface().(Enumer).Enum(), true
+	case reflect.PointerTo(t).Implements(enumerType):
+		return reflect.New(t).Interface().(Enumer).Enum(), true
+	}
+	return nil, false
+}
+
+// enumSchema maps Enumer types to an inline schema listing their values.
+func enumSchema(t reflect.Type) *jsonschema.Schema {
+	values, ok := enumValues(t)
+	if !ok {
+		return nil
+	}
+
+	s := &jsonschema.Schema{}
+
+	switch t.Kind() {
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		s.Type = "number"
+	case reflect.Bool:
+		s.Type = "boolean"
+	default:
+		return nil
+	}
+
+	s.Enum = values
+
+	return s
+}
+
+// EnumError is returned when a decoded value is not one of the values declared
+// by its Enumer type.
+type EnumError struct {
+	Path    string
+	Value   any
+	Allowed []any
+}
+
+func (e *EnumError) Error() string {
+	allowed := make([]string, len(e.Allowed))
+	for i, v := range e.Allowed {
+		allowed[i] = fmt.Sprintf("%q", fmt.Sprint(v))
+	}
+	return fmt.Sprintf("%s: %q is not one of %s", e.Path, fmt.Sprint(e.Value), strings.Join(allowed, ", "))
+}
+
+// enumTypes caches whether a type contains any Enumer, to skip walking
+// responses that can't hold one.
+var enumTypes sync.Map // reflect.Type -> bool
+
+func hasEnums(t reflect.Type) bool {
+	if has, ok := enumTypes.Load(t); ok {
+		return has.(bool)
+	}
+
+	has := typeHasEnums(t, map[reflect.Type]bool{})
+	enumTypes.Store(t, has)
+	return has
+}
+
+func typeHasEnums(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if _, ok := enumValues(t); ok {
+		return true
+	}
+
+	// self-referential types are only visited once
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if u := unionOf(t); u != nil {
+			for _, v := range u.variants {
+				if typeHasEnums(v.typ, visiting) {
+					return true
+				}
+			}
+		}
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return typeHasEnums(t.Elem(), visiting)
+	case reflect.Map:
+		return typeHasEnums(t.Key(), visiting) || typeHasEnums(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && typeHasEnums(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// validateEnums checks every Enumer value in v against its declared values.
+func validateEnums(v any) error {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !hasEnums(rv.Type()) {
+		return nil
+	}
+	return checkEnums(rv, "$")
+}
+
+func checkEnums(v reflect.Value, path string) error {
+	if values, ok := enumValues(v.Type()); ok && v.Type().Comparable() {
+		for _, allowed := range values {
+			a := reflect.ValueOf(allowed)
+			if a.Type().ConvertibleTo(v.Type()) && a.Convert(v.Type()).Interface() == v.Interface() {
+				return nil
+			}
+		}
+		return &EnumError{Path: path, Value: v.Interface(), Allowed: values}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return checkEnums(v.Elem(), path)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := checkEnums(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elemPath := fmt.Sprintf("%s[%v]", path, iter.Key().Interface())
+			if err := checkEnums(iter.Key(), elemPath); err != nil {
+				return err
+			}
+			if err := checkEnums(iter.Value(), elemPath); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || !hasEnums(field.Type) {
+				continue
+			}
+			if err := checkEnums(v.Field(i), path+"."+jsonFieldName(field)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,77 @@
+package instructor
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Response types that aren't structs (slices, maps, primitives) have no object
+// schema to hand to the model, so they are wrapped in an envelope
+// `{"result": <value>}` on the wire and unwrapped again after parsing.
+
+const envelopeField = "result"
+
+// envelopeType returns the envelope struct for t, or nil if t is a struct (or a
+// pointer to one) and can be used as is.
+func envelopeType(t reflect.Type) reflect.Type {
+	t = derefType(t)
+	if t.Kind() == reflect.Struct {
+		return nil
+	}
+
+	tag := `json:"` + envelopeField + `"`
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		tag += ` validate:"dive"`
+	}
+
+	return reflect.StructOf([]reflect.StructField{
+		{
+			Name: "Result",
+			Type: t,
+			Tag:  reflect.StructTag(tag),
+		},
+	})
+}
+
+// envelopeName names the envelope of t after the wrapped type, e.g. `Search`
+// for `Search`, `SearchList` for `[]Search` and `IntMap` for `map[string]int`.
+func envelopeName(t reflect.Type) string {
+	t = derefType(t)
+
+	if t.Name() != "" && t.PkgPath() != "" {
+		return typeName(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return envelopeName(t.Elem()) + "List"
+	case reflect.Map:
+		return envelopeName(t.Elem()) + "Map"
+	case reflect.Interface:
+		return "Value"
+	case reflect.Struct:
+		return "Response"
+	}
+
+	name := []rune(t.Kind().String())
+	name[0] = unicode.ToUpper(name[0])
+	return strings.TrimSuffix(string(name), "64")
+}
+
+// unwrapEnvelope copies the result of a decoded envelope into target, a
+// pointer to the original response type.
+func unwrapEnvelope(envelope any, target any) {
+	result := reflect.ValueOf(envelope).Elem().Field(0)
+
+	dst := reflect.ValueOf(target).Elem()
+	for dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	dst.Set(result)
+}
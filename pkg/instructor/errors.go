@@ -0,0 +1,41 @@
+package instructor
+
+import "fmt"
+
+// RefusalError is returned when the model declines to produce the requested
+// structured output.
+type RefusalError struct {
+	Provider Provider
+	Refusal  string
+}
+
+func (e *RefusalError) Error() string {
+	return fmt.Sprintf("%s model refused to respond: %s", e.Provider, e.Refusal)
+}
+
+// TruncationError is returned when generation stopped at the token limit
+// before the structured output was complete.
+type TruncationError struct {
+	Provider Provider
+	Reason   string
+}
+
+func (e *TruncationError) Error() string {
+	return fmt.Sprintf("%s response was truncated (%s); increase the max tokens of the request", e.Provider, e.Reason)
+}
+
+// ContentFilterError is returned when the prompt or the response was blocked
+// by the provider's safety or content filters.
+type ContentFilterError struct {
+	Provider Provider
+	Reason   string
+	Err      error
+}
+
+func (e *ContentFilterError) Error() string {
+	return fmt.Sprintf("%s blocked the response (%s)", e.Provider, e.Reason)
+}
+
+func (e *ContentFilterError) Unwrap() error {
+	return e.Err
+}
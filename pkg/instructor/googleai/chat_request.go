@@ -6,4 +6,9 @@ type ChatRequest struct {
 	Model   *genai.GenerativeModel
 	Session *genai.ChatSession
 	Parts   []genai.Part
+
+	// ModelName is the name Model was created with, e.g. "gemini-1.5-pro",
+	// which genai doesn't expose. Responses to requests without it aren't
+	// cached.
+	ModelName string
 }
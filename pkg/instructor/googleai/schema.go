@@ -1,117 +1,328 @@
 package googleai
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/google/generative-ai-go/genai"
 )
 
+// maxRecursionDepth is how many times a type may appear on the path from the
+// root. genai schemas can't reference themselves, so self-referential types are
+// unrolled this many levels and the recursive field is left out below that.
+const maxRecursionDepth = 3
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// enumer matches instructor.Enumer, for types declaring their allowed values.
+type enumer interface {
+	Enum() []any
+}
+
+var enumerType = reflect.TypeOf((*enumer)(nil)).Elem()
+
 // GenerateSchemaFromType converts a reflect.Type to a Schema object.
+//
+// Fields are named and marked required the same way invopop/jsonschema does
+// it for NewSchema: by their `json` tag (required unless `omitempty`), with
+// `jsonschema:"required"`, `description=...`, `enum=...`, `format=...` and
+// `nullable` honored. The legacy `schema:"description=..."` tag is still read.
 func GenerateSchemaFromType(typ reflect.Type) (*genai.Schema, error) {
-	schema := &genai.Schema{}
+	return (&Reflector{}).Reflect(typ), nil
+}
 
-	switch typ.Kind() {
-	case reflect.Struct:
-		schema.Type = genai.TypeObject
-		schema.Properties = make(map[string]*genai.Schema)
-		schema.Required = []string{}
-
-		for i := 0; i < typ.NumField(); i++ {
-			field := typ.Field(i)
-			fieldSchema, err := parseFieldSchema(field)
-			if err != nil {
-				return nil, err
-			}
+// Union describes an interface type whose values are one of several struct
+// variants, told apart by the Discriminator property holding the variant name.
+type Union struct {
+	Discriminator string
+	Names         []string
+	Types         []reflect.Type
+}
 
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == "" || jsonTag == "-" {
-				continue
-			}
+// Reflector generates genai schemas like GenerateSchemaFromType, with hooks
+// for types it can't know about on its own. The zero value is ready to use.
+type Reflector struct {
+	// Union returns the variants of an interface type, or nil if it isn't a
+	// union. genai schemas have no `anyOf`, so a union becomes a single object
+	// with the discriminator and the properties of all variants.
+	Union func(reflect.Type) *Union
+}
 
-			jsonParts := strings.Split(jsonTag, ",")
-			jsonName := jsonParts[0]
+// Reflect returns the schema of typ.
+func (r *Reflector) Reflect(typ reflect.Type) *genai.Schema {
+	g := &generator{reflector: r, seen: map[reflect.Type]int{}}
 
-			if !contains(jsonParts, "omitempty") {
-				schema.Required = append(schema.Required, jsonName)
-			}
+	schema := g.generate(typ)
+	if schema == nil {
+		schema = &genai.Schema{Type: genai.TypeObject}
+	}
 
-			schema.Properties[jsonName] = fieldSchema
+	return schema
+}
+
+type generator struct {
+	reflector *Reflector
+	seen      map[reflect.Type]int
+}
+
+// generate returns the schema of typ, or nil if typ is recursive and has
+// already been unrolled maxRecursionDepth times.
+func (g *generator) generate(typ reflect.Type) *genai.Schema {
+	nullable := false
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+		nullable = true
+	}
+
+	var schema *genai.Schema
+
+	switch {
+	case typ == timeType:
+		schema = &genai.Schema{Type: genai.TypeString, Format: "date-time"}
+
+	case typ.Kind() == reflect.Struct:
+		if g.seen[typ] >= maxRecursionDepth {
+			return nil
 		}
 
-	case reflect.Slice, reflect.Array:
-		schema.Type = genai.TypeArray
-		elemType := typ.Elem()
+		g.seen[typ]++
+		schema = g.object(typ)
+		g.seen[typ]--
 
-		itemSchema, err := GenerateSchemaFromType(elemType)
-		if err != nil {
-			return nil, err
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
+		// encoding/json encodes []byte as base64
+		schema = &genai.Schema{Type: genai.TypeString, Description: "base64 encoded"}
+
+	case typ.Kind() == reflect.Slice, typ.Kind() == reflect.Array:
+		items := g.generate(typ.Elem())
+		if items == nil {
+			return nil
 		}
+		schema = &genai.Schema{Type: genai.TypeArray, Items: items}
 
-		schema.Items = itemSchema
+	case typ.Kind() == reflect.Interface && g.union(typ) != nil:
+		schema = g.unionObject(g.union(typ))
+
+	case typ.Kind() == reflect.Map:
+		// genai has no additionalProperties, so the value type can only be hinted
+		schema = &genai.Schema{
+			Type:        genai.TypeObject,
+			Description: "Object mapping keys to " + valueTypeHint(typ.Elem()) + " values",
+		}
 
 	default:
-		schema.Type = goTypeToSchemaType(typ)
-		schema.Format = goTypeToSchemaFormat(typ)
-		schema.Nullable = isNullable(typ)
+		schema = &genai.Schema{
+			Type:   goTypeToSchemaType(typ),
+			Format: goTypeToSchemaFormat(typ),
+		}
+		setEnum(schema, enumValues(typ))
+	}
+
+	schema.Nullable = schema.Nullable || nullable
+
+	return schema
+}
+
+func enumValues(typ reflect.Type) []string {
+	var values []any
+	switch {
+	case typ.Implements(enumerType):
+		values = reflect.Zero(typ).Interface().(enumer).Enum()
+	case reflect.PointerTo(typ).Implements(enumerType):
+		values = reflect.New(typ).Interface().(enumer).Enum()
+	}
+
+	enum := make([]string, len(values))
+	for i, v := range values {
+		enum[i] = fmt.Sprint(v)
+	}
+	return enum
+}
+
+func setEnum(schema *genai.Schema, enum []string) {
+	if len(enum) == 0 {
+		return
 	}
 
-	return schema, nil
+	if schema.Type == genai.TypeString {
+		schema.Format = "enum"
+		schema.Enum = enum
+	} else {
+		// genai only supports enums of strings
+		schema.Description = appendHint(schema.Description, "One of: "+strings.Join(enum, ", "))
+	}
 }
 
-// parseFieldSchema parses a single field to a Schema, handling arrays, structs, and formats.
-func parseFieldSchema(field reflect.StructField) (*genai.Schema, error) {
-	fieldType := field.Type
+func (g *generator) union(typ reflect.Type) *Union {
+	if g.reflector.Union == nil {
+		return nil
+	}
+	return g.reflector.Union(typ)
+}
+
+// unionObject merges the variants of u into one object. Properties are
+// nullable and hint which variants they belong to.
+func (g *generator) unionObject(u *Union) *genai.Schema {
 	schema := &genai.Schema{
-		Type:     goTypeToSchemaType(fieldType),
-		Nullable: isNullable(fieldType),
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			u.Discriminator: {
+				Type:        genai.TypeString,
+				Format:      "enum",
+				Enum:        u.Names,
+				Description: "Which kind of object this is",
+			},
+		},
+		Required: []string{u.Discriminator},
 	}
 
-	// Set format for primitive types
-	schema.Format = goTypeToSchemaFormat(fieldType)
+	owners := map[string][]string{}
 
-	switch fieldType.Kind() {
-	case reflect.Slice, reflect.Array:
-		elemType := fieldType.Elem()
-		itemSchema, err := GenerateSchemaFromType(elemType)
-		if err != nil {
-			return nil, err
+	for i, typ := range u.Types {
+		variant := g.generate(typ)
+		if variant == nil {
+			continue
 		}
-		schema.Items = itemSchema
 
-	case reflect.Struct:
-		nestedSchema, err := GenerateSchemaFromType(fieldType)
-		if err != nil {
-			return nil, err
+		for name, property := range variant.Properties {
+			if name == u.Discriminator {
+				continue
+			}
+			if _, ok := schema.Properties[name]; !ok {
+				merged := *property
+				merged.Nullable = true
+				schema.Properties[name] = &merged
+			}
+			owners[name] = append(owners[name], u.Names[i])
 		}
-		schema.Type = genai.TypeObject
-		schema.Properties = nestedSchema.Properties
 	}
 
-	schemaTag := field.Tag.Get("schema")
-	if schemaTag != "" {
-		tags := parseTags(schemaTag)
+	for name, variants := range owners {
+		if len(variants) < len(u.Names) {
+			property := schema.Properties[name]
+			property.Description = appendHint(property.Description, "Only for "+strings.Join(variants, ", "))
+		}
+	}
+
+	return schema
+}
+
+func (g *generator) object(typ reflect.Type) *genai.Schema {
+	schema := &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: make(map[string]*genai.Schema),
+		Required:   []string{},
+	}
+
+	g.addFields(schema, typ)
+
+	return schema
+}
+
+// addFields adds the fields of typ to schema, inlining embedded structs like
+// encoding/json does.
+func (g *generator) addFields(schema *genai.Schema, typ reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		jsonTags := strings.Split(field.Tag.Get("json"), ",")
+		schemaTags := splitOnUnescapedCommas(field.Tag.Get("jsonschema"))
+
+		if jsonTags[0] == "-" || schemaTags[0] == "-" {
+			continue
+		}
+
+		if field.Anonymous && jsonTags[0] == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := jsonTags[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := g.generate(field.Type)
+		if fieldSchema == nil {
+			continue
+		}
+
+		// copy so tags don't leak into schemas shared between fields
+		fieldSchema = withTags(*fieldSchema, field, schemaTags)
+
+		required := !contains(jsonTags[1:], "omitempty") || contains(schemaTags, "required")
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = fieldSchema
+	}
+}
+
+// withTags applies the `jsonschema` (and legacy `schema`) tags of field.
+func withTags(schema genai.Schema, field reflect.StructField, schemaTags []string) *genai.Schema {
+	var enum []string
+
+	// descriptions of the type itself (e.g. the values of a map) are kept as hints
+	typeHint := schema.Description
+
+	for _, tag := range schemaTags {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok {
+			if key == "nullable" {
+				schema.Nullable = true
+			}
+			continue
+		}
+
+		switch key {
+		case "description":
+			schema.Description = appendHint(value, typeHint)
+		case "enum":
+			enum = append(enum, value)
+		case "format":
+			schema.Format = value
+		}
+	}
+
+	if desc := field.Tag.Get("jsonschema_description"); desc != "" {
+		schema.Description = appendHint(desc, typeHint)
+	}
+
+	if legacy := field.Tag.Get("schema"); legacy != "" {
+		tags := parseTags(legacy)
 		if desc, ok := tags["description"]; ok {
-			schema.Description = desc
+			schema.Description = appendHint(desc, typeHint)
 		}
 		if example, ok := tags["example"]; ok {
-			schema.Enum = strings.Split(example, ",")
+			schema.Description = appendHint(schema.Description, "Example: "+example)
 		}
 	}
 
-	return schema, nil
-}
+	setEnum(&schema, enum)
 
-// Helper functions
+	if !supportedFormat(schema.Type, schema.Format) {
+		schema.Description = appendHint(schema.Description, "Format: "+schema.Format)
+		schema.Format = ""
+	}
 
-func isNullable(typ reflect.Type) bool {
-	return typ.Kind() == reflect.Ptr ||
-		typ.Kind() == reflect.Interface ||
-		typ.Kind() == reflect.Slice ||
-		typ.Kind() == reflect.Map ||
-		typ.Kind() == reflect.Chan
+	return &schema
 }
 
+// Helper functions
+
 func contains(slice []string, value string) bool {
 	for _, v := range slice {
 		if v == value {
@@ -135,11 +346,66 @@ func parseTags(tag string) map[string]string {
 	return tags
 }
 
+// splitOnUnescapedCommas splits a `jsonschema` tag like invopop/jsonschema,
+// where `\,` is a literal comma.
+func splitOnUnescapedCommas(tag string) []string {
+	var parts []string
+
+	for _, part := range strings.Split(tag, ",") {
+		if n := len(parts); n > 0 && strings.HasSuffix(parts[n-1], `\`) {
+			parts[n-1] = strings.TrimSuffix(parts[n-1], `\`) + "," + part
+			continue
+		}
+		parts = append(parts, part)
+	}
+
+	return parts
+}
+
+func appendHint(description, hint string) string {
+	if hint == "" {
+		return description
+	}
+	if description == "" {
+		return hint
+	}
+	return description + " (" + hint + ")"
+}
+
+// supportedFormat reports whether genai accepts format for the given type.
+func supportedFormat(typ genai.Type, format string) bool {
+	switch format {
+	case "":
+		return true
+	case "enum", "date-time":
+		return typ == genai.TypeString
+	case "int32", "int64":
+		return typ == genai.TypeInteger
+	case "float", "double":
+		return typ == genai.TypeNumber
+	}
+	return false
+}
+
+func valueTypeHint(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return "date-time"
+	}
+	if typ.Kind() == reflect.Map {
+		return "object"
+	}
+	return strings.ToLower(strings.TrimPrefix(goTypeToSchemaType(typ).String(), "Type"))
+}
+
 func goTypeToSchemaType(typ reflect.Type) genai.Type {
 	switch typ.Kind() {
 	case reflect.String:
 		return genai.TypeString
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return genai.TypeInteger
 	case reflect.Float32, reflect.Float64:
 		return genai.TypeNumber
@@ -147,7 +413,7 @@ func goTypeToSchemaType(typ reflect.Type) genai.Type {
 		return genai.TypeBoolean
 	case reflect.Slice, reflect.Array:
 		return genai.TypeArray
-	case reflect.Struct:
+	case reflect.Struct, reflect.Map:
 		return genai.TypeObject
 	default:
 		return genai.TypeString
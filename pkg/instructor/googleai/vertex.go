@@ -0,0 +1,222 @@
+package googleai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+)
+
+// vertexScope is the OAuth scope of Vertex AI.
+const vertexScope = "https://www.googleapis.com/auth/cloud-platform"
+
+type vertexConfig struct {
+	baseURL     string
+	tokenSource oauth2.TokenSource
+	httpClient  *http.Client
+}
+
+// VertexOption configures NewVertexClient.
+type VertexOption func(*vertexConfig)
+
+// WithBaseURL sends requests to baseURL instead of the regional Vertex AI
+// endpoint, e.g. for Private Service Connect.
+func WithBaseURL(baseURL string) VertexOption {
+	return func(c *vertexConfig) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithTokenSource authenticates with ts instead of Application Default
+// Credentials.
+func WithTokenSource(ts oauth2.TokenSource) VertexOption {
+	return func(c *vertexConfig) {
+		c.tokenSource = ts
+	}
+}
+
+// WithHTTPClient sends the requests with client, http.DefaultClient by default.
+func WithHTTPClient(client *http.Client) VertexOption {
+	return func(c *vertexConfig) {
+		c.httpClient = client
+	}
+}
+
+// NewVertexClient returns a genai client for Gemini on Vertex AI, in the given
+// Google Cloud project and location (e.g. "us-central1"). Models are named as
+// for the Gemini API, e.g. client.GenerativeModel("gemini-1.5-pro"), and the
+// client is used with instructor.FromGoogleAI as usual.
+//
+// Requests are authenticated with Application Default Credentials unless
+// WithTokenSource is given. Only content generation and token counting are
+// available on Vertex AI, files and cached contents are not.
+func NewVertexClient(ctx context.Context, project, location string, opts ...VertexOption) (*genai.Client, error) {
+	if project == "" || location == "" {
+		return nil, fmt.Errorf("vertex ai requires a project and a location")
+	}
+
+	config := &vertexConfig{httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.baseURL == "" {
+		config.baseURL = fmt.Sprintf("https://%s-aiplatform.googleapis.com", location)
+		if location == "global" {
+			config.baseURL = "https://aiplatform.googleapis.com"
+		}
+	}
+
+	if config.tokenSource == nil {
+		ts, err := google.DefaultTokenSource(ctx, vertexScope)
+		if err != nil {
+			return nil, fmt.Errorf("finding default credentials: %w", err)
+		}
+		config.tokenSource = ts
+	}
+
+	baseURL, err := url.Parse(config.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing base url: %w", err)
+	}
+
+	transport := &vertexTransport{
+		baseURL: baseURL,
+		parent:  fmt.Sprintf("projects/%s/locations/%s/publishers/google", project, location),
+		base: &oauth2.Transport{
+			Source: config.tokenSource,
+			Base:   config.httpClient.Transport,
+		},
+	}
+
+	httpClient := *config.httpClient
+	httpClient.Transport = transport
+
+	// genai creates its cache client without the http client, it needs the
+	// credentials on their own
+	return genai.NewClient(ctx, option.WithHTTPClient(&httpClient), option.WithTokenSource(config.tokenSource))
+}
+
+// vertexGenaiVersion is the genai release whose Gemini API requests
+// vertexTransport rewrites. genai doesn't document its wire format, so the
+// rewrites have to be checked against the requests of any other release
+// before upgrading, and this bumped.
+const vertexGenaiVersion = "v0.18.0"
+
+// vertexHarmCategories maps the harm categories of the Gemini API to the
+// numbers Vertex AI uses for them.
+var vertexHarmCategories = map[float64]float64{
+	float64(genai.HarmCategoryHarassment):       3,
+	float64(genai.HarmCategoryHateSpeech):       1,
+	float64(genai.HarmCategorySexuallyExplicit): 4,
+	float64(genai.HarmCategoryDangerousContent): 2,
+}
+
+// vertexTransport rewrites the Gemini API requests of genai to the
+// equivalent Vertex AI requests, for the genai release of vertexGenaiVersion.
+type vertexTransport struct {
+	baseURL *url.URL
+	parent  string
+	base    http.RoundTripper
+}
+
+func (t *vertexTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	model, ok := strings.CutPrefix(req.URL.Path, "/v1beta/models/")
+	if !ok {
+		return nil, fmt.Errorf("%s %s is not available on Vertex AI", req.Method, req.URL.Path)
+	}
+
+	name, _, _ := strings.Cut(model, ":")
+	resource := t.parent + "/models/" + name
+
+	body, err := vertexBody(req, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	// copy as round trippers must not modify the request
+	out := req.Clone(req.Context())
+
+	out.URL.Scheme = t.baseURL.Scheme
+	out.URL.Host = t.baseURL.Host
+	out.URL.Path = t.baseURL.Path + "/v1/" + t.parent + "/models/" + model
+	out.URL.RawPath = ""
+	out.Host = ""
+
+	// enums are numbered differently, ask for their names instead of numbers
+	query := out.URL.Query()
+	query.Set("$alt", "json")
+	out.URL.RawQuery = query.Encode()
+
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.ContentLength = int64(len(body))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	return t.base.RoundTrip(out)
+}
+
+// vertexCountTokensFields are the fields of a nested generateContentRequest
+// that a Vertex AI countTokens request takes at its top level.
+var vertexCountTokensFields = []string{"contents", "systemInstruction", "tools", "generationConfig"}
+
+// vertexBody returns the body of req with the model named by its Vertex AI
+// resource name and the harm categories renumbered, nil if req has no body.
+// The generateContentRequest of countTokens is flattened, as Vertex AI takes
+// its fields directly.
+func vertexBody(req *http.Request, resource string) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, fmt.Errorf("decoding request: %w", err)
+	}
+
+	if nested, ok := body["generateContentRequest"].(map[string]any); ok {
+		delete(body, "generateContentRequest")
+		for _, field := range vertexCountTokensFields {
+			if value, ok := nested[field]; ok {
+				body[field] = value
+			}
+		}
+	}
+
+	if _, ok := body["model"]; ok {
+		body["model"] = resource
+	}
+
+	if settings, ok := body["safetySettings"].([]any); ok {
+		for _, s := range settings {
+			setting, ok := s.(map[string]any)
+			if !ok {
+				continue
+			}
+			number, _ := setting["category"].(float64)
+			if category, ok := vertexHarmCategories[number]; ok {
+				setting["category"] = category
+			}
+		}
+	}
+
+	return json.Marshal(body)
+}
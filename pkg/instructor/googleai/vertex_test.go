@@ -0,0 +1,28 @@
+package googleai
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestVertexGenaiVersion(t *testing.T) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Skip("no build info")
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path != "github.com/google/generative-ai-go" {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		if dep.Version != vertexGenaiVersion {
+			t.Errorf("genai is %s, but vertexTransport rewrites the requests of %s", dep.Version, vertexGenaiVersion)
+		}
+		return
+	}
+
+	t.Error("genai not found in the build info")
+}
@@ -2,6 +2,8 @@ package instructor
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/google/generative-ai-go/genai"
@@ -32,18 +34,175 @@ func (i *InstructorGoogleAI) chat(ctx context.Context, request interface{}, sche
 	}
 	req.Model.SetCandidateCount(1)
 
-	switch i.Mode() {
+	mode := i.requestMode(request, false)
+
+	switch mode {
 	case ModeToolCall:
 		return i.chatToolCall(ctx, req, schema)
 	case ModeJSON:
 		return i.chatJSON(ctx, req, schema)
 	default:
-		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", i.Mode(), i.Provider())
+		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorGoogleAI) modelName(request interface{}) string {
+	req, ok := request.(*googleai.ChatRequest)
+	if !ok {
+		return ""
+	}
+	return req.ModelName
+}
+
+func (i *InstructorGoogleAI) increaseMaxTokens(request interface{}) (interface{}, bool) {
+	// the chat session already recorded the truncated turn in its history,
+	// so the request can't be replayed with a higher limit
+	return request, false
+}
+
+// googleAIToolName is the name of the single function the response schema is
+// declared as in tool call mode.
+const googleAIToolName = "respond"
+
+func (i *InstructorGoogleAI) addUserMessage(request interface{}, message string) interface{} {
+	req, ok := request.(*googleai.ChatRequest)
+	if !ok {
+		return request
+	}
+
+	// the session holds the history, answer the function call of the previous
+	// reply (if any) along with the message
+	var parts []genai.Part
+	if history := req.Session.History; len(history) > 0 {
+		for _, part := range history[len(history)-1].Parts {
+			if call, ok := part.(genai.FunctionCall); ok {
+				parts = append(parts, genai.FunctionResponse{
+					Name:     call.Name,
+					Response: map[string]any{"result": recordedReply},
+				})
+			}
+		}
+	}
+	parts = append(parts, genai.Text(message))
+
+	return &googleai.ChatRequest{
+		Model:     req.Model,
+		Session:   req.Session,
+		Parts:     parts,
+		ModelName: req.ModelName,
+	}
+}
+
+// addReply leaves the request as is, the session records the reply itself.
+func (i *InstructorGoogleAI) addReply(request interface{}, response interface{}, reply string) interface{} {
+	return request
+}
+
+// addToolResults is not supported yet, RunAgent rejects Google AI clients.
+func (i *InstructorGoogleAI) addToolResults(request interface{}, calls []parallelCall, results []toolResult) interface{} {
+	return request
+}
+
+func (i *InstructorGoogleAI) reask(request interface{}, response string, feedback string) interface{} {
+	req, ok := request.(*googleai.ChatRequest)
+	if !ok {
+		return request
+	}
+
+	// the session already holds the previous turn, answer its function calls
+	// (if any) with the feedback
+	var parts []genai.Part
+	if history := req.Session.History; len(history) > 0 {
+		for _, part := range history[len(history)-1].Parts {
+			if call, ok := part.(genai.FunctionCall); ok {
+				parts = append(parts, genai.FunctionResponse{
+					Name:     call.Name,
+					Response: map[string]any{"error": feedback},
+				})
+			}
+		}
+	}
+	if len(parts) == 0 {
+		parts = []genai.Part{genai.Text(feedback)}
+	}
+
+	return &googleai.ChatRequest{
+		Model:     req.Model,
+		Session:   req.Session,
+		Parts:     parts,
+		ModelName: req.ModelName,
 	}
 }
 
 func (i *InstructorGoogleAI) chatToolCall(ctx context.Context, request *googleai.ChatRequest, schema *genai.Schema) (string, *genai.GenerateContentResponse, error) {
-	panic("tool call not implemented googleai")
+	request.Model.Tools = []*genai.Tool{{
+		FunctionDeclarations: []*genai.FunctionDeclaration{{
+			Name:        googleAIToolName,
+			Description: "Respond with the requested data.",
+			Parameters:  schema,
+		}},
+	}}
+	request.Model.ToolConfig = &genai.ToolConfig{
+		FunctionCallingConfig: &genai.FunctionCallingConfig{
+			Mode:                 genai.FunctionCallingAny,
+			AllowedFunctionNames: []string{googleAIToolName},
+		},
+	}
+
+	resp, err := request.Session.SendMessage(ctx, request.Parts...)
+	if err != nil {
+		return "", nil, googleAIError(err)
+	}
+
+	if err := checkGoogleAIResponse(resp); err != nil {
+		return "", nilGoogleAIRespWithUsage(resp), err
+	}
+
+	var calls []json.RawMessage
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if call, ok := part.(genai.FunctionCall); ok {
+			arguments, err := json.Marshal(call.Args)
+			if err != nil {
+				return "", nilGoogleAIRespWithUsage(resp), err
+			}
+			calls = append(calls, arguments)
+		}
+	}
+
+	switch len(calls) {
+	case 0:
+		return "", nilGoogleAIRespWithUsage(resp), errors.New("received no tool calls from model, expected at least 1")
+	case 1:
+		return string(calls[0]), resp, nil
+	}
+
+	// parallel calls of the items wrapper are merged, others are returned as an array
+	var merged struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	itemsOnly := len(schema.Properties) == 1 && schema.Properties["items"] != nil
+	for _, call := range calls {
+		var items struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(call, &items); err != nil || items.Items == nil {
+			itemsOnly = false
+			break
+		}
+		merged.Items = append(merged.Items, items.Items...)
+	}
+
+	var text []byte
+	if itemsOnly {
+		text, err = json.Marshal(merged)
+	} else {
+		text, err = json.Marshal(calls)
+	}
+	if err != nil {
+		return "", nilGoogleAIRespWithUsage(resp), err
+	}
+
+	return string(text), resp, nil
 }
 
 func (i *InstructorGoogleAI) chatJSON(ctx context.Context, request *googleai.ChatRequest, schema *genai.Schema) (string, *genai.GenerateContentResponse, error) {
@@ -52,7 +211,11 @@ func (i *InstructorGoogleAI) chatJSON(ctx context.Context, request *googleai.Cha
 
 	resp, err := request.Session.SendMessage(ctx, request.Parts...)
 	if err != nil {
-		return "", nil, err
+		return "", nil, googleAIError(err)
+	}
+
+	if err := checkGoogleAIResponse(resp); err != nil {
+		return "", nilGoogleAIRespWithUsage(resp), err
 	}
 
 	var respText string
@@ -65,12 +228,50 @@ func (i *InstructorGoogleAI) chatJSON(ctx context.Context, request *googleai.Cha
 	return respText, resp, nil
 }
 
+// googleAIError maps genai's blocked prompt/candidate error to a ContentFilterError.
+func googleAIError(err error) error {
+	var blocked *genai.BlockedError
+	if !errors.As(err, &blocked) {
+		return err
+	}
+
+	reason := "blocked"
+	if blocked.PromptFeedback != nil {
+		reason = "prompt " + blocked.PromptFeedback.BlockReason.String()
+	} else if blocked.Candidate != nil {
+		reason = blocked.Candidate.FinishReason.String()
+	}
+
+	return &ContentFilterError{Provider: ProviderGoogleAI, Reason: reason, Err: err}
+}
+
+func checkGoogleAIResponse(resp *genai.GenerateContentResponse) error {
+	if len(resp.Candidates) == 0 {
+		return errors.New("received no candidates from model")
+	}
+
+	candidate := resp.Candidates[0]
+
+	switch candidate.FinishReason {
+	case genai.FinishReasonMaxTokens:
+		return &TruncationError{Provider: ProviderGoogleAI, Reason: candidate.FinishReason.String()}
+	case genai.FinishReasonSafety, genai.FinishReasonRecitation:
+		return &ContentFilterError{Provider: ProviderGoogleAI, Reason: candidate.FinishReason.String()}
+	}
+
+	if candidate.Content == nil {
+		return errors.New("received no content from model")
+	}
+
+	return nil
+}
+
 func (i *InstructorGoogleAI) emptyResponseWithUsageSum(usage *UsageSum) interface{} {
 	return &genai.GenerateContentResponse{
 		UsageMetadata: &genai.UsageMetadata{
-			PromptTokenCount:        int32(usage.InputTokens),
-			CachedContentTokenCount: int32(usage.OutputTokens),
-			TotalTokenCount:         int32(usage.TotalTokens),
+			PromptTokenCount:     int32(usage.InputTokens),
+			CandidatesTokenCount: int32(usage.OutputTokens),
+			TotalTokenCount:      int32(usage.TotalTokens),
 		},
 	}
 }
@@ -89,7 +290,13 @@ func (i *InstructorGoogleAI) emptyResponseWithResponseUsage(response interface{}
 func (i *InstructorGoogleAI) addUsageSumToResponse(response interface{}, usage *UsageSum) (interface{}, error) {
 	resp, ok := response.(*genai.GenerateContentResponse)
 	if !ok {
-		return response, fmt.Errorf("internal type error: expected *openai.ChatCompletionResponse, got %T", response)
+		return response, fmt.Errorf("internal type error: expected *genai.GenerateContentResponse, got %T", response)
+	}
+	if resp == nil {
+		return i.emptyResponseWithUsageSum(usage), nil
+	}
+	if resp.UsageMetadata == nil {
+		resp.UsageMetadata = &genai.UsageMetadata{}
 	}
 
 	resp.UsageMetadata.PromptTokenCount += int32(usage.InputTokens)
@@ -101,7 +308,7 @@ func (i *InstructorGoogleAI) addUsageSumToResponse(response interface{}, usage *
 
 func (i *InstructorGoogleAI) countUsageFromResponse(response interface{}, usage *UsageSum) *UsageSum {
 	resp, ok := response.(*genai.GenerateContentResponse)
-	if !ok {
+	if !ok || resp == nil || resp.UsageMetadata == nil {
 		return usage
 	}
 
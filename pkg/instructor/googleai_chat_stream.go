@@ -30,11 +30,13 @@ func (i *InstructorGoogleAI) chatStream(ctx context.Context, request interface{}
 	}
 	req.Model.SetCandidateCount(1)
 
-	switch i.Mode() {
+	mode := i.requestMode(request, true)
+
+	switch mode {
 	case ModeJSON:
 		return i.chatJSONStream(ctx, req, schema)
 	default:
-		return nil, fmt.Errorf("mode '%s' is not supported for %s", i.Mode(), i.Provider())
+		return nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
 	}
 }
 
@@ -61,6 +63,15 @@ func (i *InstructorGoogleAI) chatJSONStream(ctx context.Context, request *google
 				return
 			}
 
+			// each chunk holds the usage so far
+			if resp.UsageMetadata != nil {
+				recordStreamUsage(ctx, i.countUsageFromResponse(resp, &UsageSum{}))
+			}
+
+			if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+				continue
+			}
+
 			// Extract and stream response content
 			for _, part := range resp.Candidates[0].Content.Parts {
 				if textPart, ok := part.(genai.Text); ok {
@@ -9,11 +9,21 @@ type InstructorGoogleAI struct {
 
 	provider   Provider
 	mode       Mode
+	streamMode Mode
+	// auto is set if the modes were picked by ModeAuto
+	auto       bool
 	maxRetries int
 	validate   bool
+
+	rateLimiter       *RateLimiter
+	cache             *ResponseCache
+	truncationRetries int
+
+	// err is why the client can't be used, e.g. an unsupported mode
+	err error
 }
 
-var _ Instructor = &InstructorAnthropic{}
+var _ Instructor = &InstructorGoogleAI{}
 
 func FromGoogleAI(client *genai.Client, opts ...Options) *InstructorGoogleAI {
 	options := mergeOptions(opts...)
@@ -22,10 +32,15 @@ func FromGoogleAI(client *genai.Client, opts ...Options) *InstructorGoogleAI {
 		Client: client,
 
 		provider:   ProviderGoogleAI,
-		mode:       *options.Mode,
 		maxRetries: *options.MaxRetries,
 		validate:   *options.validate,
+
+		rateLimiter:       options.rateLimiter,
+		cache:             options.cache,
+		truncationRetries: *options.truncationRetries,
 	}
+	i.mode, i.streamMode, i.auto, i.err = resolveModes(i.provider, ProviderModes(i.provider), options.Mode)
+
 	return i
 }
 
@@ -37,9 +52,31 @@ func (i *InstructorGoogleAI) Mode() string {
 	return i.mode
 }
 
+func (i *InstructorGoogleAI) StreamMode() Mode {
+	return i.streamMode
+}
+
+// requestMode returns the mode of a synchronous or streaming request, which
+// with ModeAuto is the best mode the model of the request supports.
+func (i *InstructorGoogleAI) requestMode(request interface{}, stream bool) Mode {
+	return modelMode(i, i.auto, ProviderModes(i.provider), request, stream)
+}
+
 func (i *InstructorGoogleAI) Provider() string {
 	return i.provider
 }
 func (i *InstructorGoogleAI) Validate() bool {
 	return i.validate
 }
+func (i *InstructorGoogleAI) RateLimiter() *RateLimiter {
+	return i.rateLimiter
+}
+func (i *InstructorGoogleAI) Cache() *ResponseCache {
+	return i.cache
+}
+func (i *InstructorGoogleAI) TruncationRetries() int {
+	return i.truncationRetries
+}
+func (i *InstructorGoogleAI) Err() error {
+	return i.err
+}
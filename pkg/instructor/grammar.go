@@ -0,0 +1,372 @@
+package instructor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// maxIntegerDigits bounds the digits of numbers in grammars, so the output
+// fits in an int64.
+const maxIntegerDigits = 15
+
+// GrammarError lists the parts of a schema that can't be expressed as a GBNF
+// grammar. It is returned before any request is sent.
+type GrammarError struct {
+	Issues []string
+}
+
+func (e *GrammarError) Error() string {
+	return "schema can't be converted to a grammar:\n  - " + strings.Join(e.Issues, "\n  - ")
+}
+
+// grammarPrimitives are the rules shared by all grammars, added as they are
+// referenced.
+var grammarPrimitives = map[string]string{
+	"space":         `" "?`,
+	"value":         `object | array | string | number | boolean | null`,
+	"object":        `"{" space ( string ":" space value ( "," space string ":" space value )* )? "}" space`,
+	"array":         `"[" space ( value ( "," space value )* )? "]" space`,
+	"string":        `"\"" char* "\"" space`,
+	"char":          `[^"\\\x7F\x00-\x1F] | [\\] ( ["\\/bfnrt] | "u" hex hex hex hex )`,
+	"hex":           `[0-9a-fA-F]`,
+	"integral-part": `[0] | [1-9] ` + repeatRule("[0-9]", 0, maxIntegerDigits-1),
+	"decimal-part":  `[0-9] ` + repeatRule("[0-9]", 0, maxIntegerDigits-1),
+	"integer":       `"-"? integral-part space`,
+	"uinteger":      `integral-part space`,
+	"number":        `"-"? integral-part ( "." decimal-part )? ( [eE] [-+]? [0-9] [0-9]? )? space`,
+	"boolean":       `( "true" | "false" ) space`,
+	"null":          `"null" space`,
+	"base64":        `"\"" ( b64 b64 b64 b64 )* ( b64 b64 "==" | b64 b64 b64 "=" )? "\"" space`,
+	"b64":           `[A-Za-z0-9+/]`,
+	"date":          `[0-9] [0-9] [0-9] [0-9] "-" ( ( "01" | "03" | "05" | "07" | "08" | "10" | "12" ) "-" ( day | "29" | "30" | "31" ) | ( "04" | "06" | "09" | "11" ) "-" ( day | "29" | "30" ) | "02-" day )`,
+	"day":           `"0" [1-9] | "1" [0-9] | "2" [0-8]`,
+	"time":          `( [01] [0-9] | "2" [0-3] ) ":" [0-5] [0-9] ":" [0-5] [0-9] ( "." [0-9]+ )? ( "Z" | [+-] ( [01] [0-9] | "2" [0-3] ) ":" [0-5] [0-9] )`,
+	"date-time":     `"\"" date "T" time "\"" space`,
+}
+
+// grammarDependencies are the primitives each primitive references.
+var grammarDependencies = map[string][]string{
+	"value":         {"object", "array", "string", "number", "boolean", "null"},
+	"object":        {"space", "string", "value"},
+	"array":         {"space", "value"},
+	"string":        {"char", "space"},
+	"char":          {"hex"},
+	"integer":       {"integral-part", "space"},
+	"uinteger":      {"integral-part", "space"},
+	"number":        {"integral-part", "decimal-part", "space"},
+	"boolean":       {"space"},
+	"null":          {"space"},
+	"base64":        {"b64", "space"},
+	"date-time":     {"date", "time", "space"},
+	"date":          {"day"},
+	"integral-part": nil,
+}
+
+var invalidRuleChars = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// ToGBNF returns a GBNF grammar, as used by llama.cpp, matching the JSON
+// instances of s. The grammar's root rule is `root`:
+//
+//   - properties are generated in order, required ones first, and no other
+//     properties are allowed
+//   - `$ref`, `anyOf`/`oneOf`, `enum`, `const`, maps and arrays (with
+//     `minItems`) are supported, as are the `date-time` format and base64
+//     content encoding of time.Time and []byte. Days are checked against
+//     their month, so February 29th is never generated
+//   - integers with a `minimum` of 0 or more have no sign, and numbers have at
+//     most 15 digits before the decimal point and exponents of 2 digits
+//
+// Constructs a grammar can't represent (`allOf`, `pattern`, conditionals,
+// `patternProperties`) are reported as a *GrammarError.
+func ToGBNF(s *jsonschema.Schema) (string, error) {
+	g := &grammarGenerator{
+		defs:  s.Definitions,
+		rules: map[string]string{},
+	}
+
+	g.rules["root"] = g.visit(s, "root", "#")
+
+	if len(g.issues) > 0 {
+		return "", &GrammarError{Issues: g.issues}
+	}
+
+	names := make([]string, 0, len(g.rules))
+	for name := range g.rules {
+		if name != "root" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "root ::= %s\n", g.rules["root"])
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s ::= %s\n", name, g.rules[name])
+	}
+
+	return b.String(), nil
+}
+
+type grammarGenerator struct {
+	defs   jsonschema.Definitions
+	rules  map[string]string
+	issues []string
+}
+
+func (g *grammarGenerator) issue(path string, format string, args ...any) {
+	g.issues = append(g.issues, path+": "+fmt.Sprintf(format, args...))
+}
+
+// primitive adds the primitive rule name and those it references, and returns
+// its name.
+func (g *grammarGenerator) primitive(name string) string {
+	if _, ok := g.rules[name]; ok {
+		return name
+	}
+
+	g.rules[name] = grammarPrimitives[name]
+	for _, dep := range grammarDependencies[name] {
+		g.primitive(dep)
+	}
+
+	return name
+}
+
+// rule adds a rule named after name, made unique, and returns the name.
+func (g *grammarGenerator) rule(name string, body string) string {
+	name = strings.Trim(invalidRuleChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		name = "rule"
+	}
+
+	unique := name
+	for i := 1; ; i++ {
+		existing, ok := g.rules[unique]
+		if !ok || existing == body {
+			break
+		}
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	g.rules[unique] = body
+	return unique
+}
+
+// visit returns the rule body matching instances of s.
+func (g *grammarGenerator) visit(s *jsonschema.Schema, name string, path string) string {
+	if s == nil {
+		return g.primitive("value")
+	}
+
+	if s.AllOf != nil {
+		g.issue(path, "allOf is not supported")
+	}
+	if s.Not != nil || s.If != nil || s.Then != nil || s.Else != nil || s.DependentSchemas != nil || s.DependentRequired != nil {
+		g.issue(path, "conditional keywords (not, if/then/else, dependent*) are not supported")
+	}
+	if s.PatternProperties != nil {
+		g.issue(path, "patternProperties is not supported")
+	}
+	if s.Pattern != "" {
+		g.issue(path, "pattern is not supported")
+	}
+
+	switch {
+	case s.Ref != "":
+		return g.ref(s.Ref, path)
+
+	case s.Const != nil:
+		return g.literals([]any{s.Const}, path)
+
+	case s.Enum != nil:
+		return g.literals(s.Enum, path)
+
+	case s.AnyOf != nil || s.OneOf != nil:
+		variants := append(append([]*jsonschema.Schema{}, s.AnyOf...), s.OneOf...)
+		alternatives := make([]string, len(variants))
+		for idx, variant := range variants {
+			alternatives[idx] = g.rule(fmt.Sprintf("%s-%d", name, idx), g.visit(variant, fmt.Sprintf("%s-%d", name, idx), fmt.Sprintf("%s/anyOf/%d", path, idx)))
+		}
+		return strings.Join(alternatives, " | ")
+	}
+
+	switch s.Type {
+	case "object":
+		return g.object(s, name, path)
+
+	case "array":
+		item := g.rule(name+"-item", g.visit(s.Items, name+"-item", path+"/items"))
+		more := fmt.Sprintf(`( "," space %s )*`, item)
+		g.primitive("space")
+		if s.MinItems != nil && *s.MinItems > 0 {
+			return fmt.Sprintf(`"[" space %s %s "]" space`, item, more)
+		}
+		return fmt.Sprintf(`"[" space ( %s %s )? "]" space`, item, more)
+
+	case "string":
+		switch {
+		case s.Format == "date-time":
+			return g.primitive("date-time")
+		case s.ContentEncoding == "base64":
+			return g.primitive("base64")
+		}
+		return g.primitive("string")
+
+	case "integer":
+		if minimum, err := s.Minimum.Float64(); err == nil && minimum >= 0 {
+			return g.primitive("uinteger")
+		}
+		return g.primitive("integer")
+
+	case "number":
+		return g.primitive("number")
+
+	case "boolean":
+		return g.primitive("boolean")
+
+	case "null":
+		return g.primitive("null")
+
+	case "":
+		return g.primitive("value")
+	}
+
+	g.issue(path, "type %q is not supported", s.Type)
+	return g.primitive("value")
+}
+
+func (g *grammarGenerator) ref(ref string, path string) string {
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	def, found := g.defs[name]
+	if !ok || !found {
+		g.issue(path, "reference %q is not a definition of the schema", ref)
+		return g.primitive("value")
+	}
+
+	ruleName := strings.Trim(invalidRuleChars.ReplaceAllString(name, "-"), "-")
+	if _, ok := grammarPrimitives[ruleName]; ok || ruleName == "root" {
+		ruleName += "-def"
+	}
+	if _, ok := g.rules[ruleName]; !ok {
+		// reserve the name first, so recursive references end here
+		g.rules[ruleName] = ""
+		g.rules[ruleName] = g.visit(def, ruleName, "#/$defs/"+name)
+	}
+	return ruleName
+}
+
+// literals returns a rule body matching exactly the JSON encoding of values.
+func (g *grammarGenerator) literals(values []any, path string) string {
+	alternatives := make([]string, 0, len(values))
+	for _, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			g.issue(path, "enum value %v can't be encoded: %s", v, err)
+			continue
+		}
+		alternatives = append(alternatives, quoteGBNF(string(b)))
+	}
+	g.primitive("space")
+	return "( " + strings.Join(alternatives, " | ") + " ) space"
+}
+
+func (g *grammarGenerator) object(s *jsonschema.Schema, name string, path string) string {
+	g.primitive("space")
+
+	if s.Properties == nil || s.Properties.Len() == 0 {
+		if s.AdditionalProperties == nil || isFalseSchema(s.AdditionalProperties) {
+			if s.AdditionalProperties == nil {
+				return g.primitive("object")
+			}
+			return `"{" space "}" space`
+		}
+
+		// a map, with keys of any name
+		value := g.rule(name+"-value", g.visit(s.AdditionalProperties, name+"-value", path+"/additionalProperties"))
+		kv := g.rule(name+"-entry", fmt.Sprintf(`%s ":" space %s`, g.primitive("string"), value))
+		return fmt.Sprintf(`"{" space ( %s ( "," space %s )* )? "}" space`, kv, kv)
+	}
+
+	required := make(map[string]bool, len(s.Required))
+	for _, key := range s.Required {
+		required[key] = true
+	}
+
+	var requiredKVs, optionalKVs []string
+	for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+		propName := name + "-" + pair.Key
+		value := g.rule(propName, g.visit(pair.Value, propName, path+"/properties/"+pair.Key))
+
+		key, _ := json.Marshal(pair.Key)
+		kv := g.rule(propName+"-kv", fmt.Sprintf(`%s space ":" space %s`, quoteGBNF(string(key)), value))
+
+		if required[pair.Key] {
+			requiredKVs = append(requiredKVs, kv)
+		} else {
+			optionalKVs = append(optionalKVs, kv)
+		}
+	}
+
+	body := `"{" space ` + strings.Join(requiredKVs, ` "," space `)
+
+	if len(optionalKVs) > 0 {
+		// any subset of the optional properties, in order
+		alternatives := g.optionalProperties(optionalKVs)
+
+		if len(requiredKVs) > 0 {
+			body += ` ( "," space ( ` + strings.Join(alternatives, " | ") + ` ) )?`
+		} else {
+			body += `( ` + strings.Join(alternatives, " | ") + ` )?`
+		}
+	}
+
+	return body + ` "}" space`
+}
+
+// optionalProperties returns, for every index of kvs, a rule body matching
+// that property followed by any subset of the later ones in order. The bodies
+// are built from the last property back, so each suffix gets a single rest
+// rule reused by all properties before it.
+func (g *grammarGenerator) optionalProperties(kvs []string) []string {
+	bodies := make([]string, len(kvs))
+	for idx := len(kvs) - 1; idx >= 0; idx-- {
+		bodies[idx] = kvs[idx]
+		if idx < len(kvs)-1 {
+			rest := g.rule(kvs[idx]+"-rest", `( "," space ( `+strings.Join(bodies[idx+1:], " | ")+` ) )?`)
+			bodies[idx] += " " + rest
+		}
+	}
+	return bodies
+}
+
+// quoteGBNF returns s as a GBNF string literal.
+func quoteGBNF(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// repeatRule returns a rule body matching item between min and max times.
+func repeatRule(item string, min int, max int) string {
+	parts := make([]string, 0, min+1)
+	for i := 0; i < min; i++ {
+		parts = append(parts, item)
+	}
+
+	optional := ""
+	for i := min; i < max; i++ {
+		optional = "( " + item + " " + optional + ")?"
+		optional = strings.ReplaceAll(optional, "  ", " ")
+	}
+	if optional != "" {
+		parts = append(parts, optional)
+	}
+
+	return strings.Join(parts, " ")
+}
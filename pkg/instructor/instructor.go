@@ -11,8 +11,17 @@ var validate *validator.Validate
 type Instructor interface {
 	Provider() Provider
 	Mode() Mode
+	// StreamMode is the mode of streaming calls, which differs from Mode
+	// with ModeAuto.
+	StreamMode() Mode
 	MaxRetries() int
 	Validate() bool
+	RateLimiter() *RateLimiter
+	Cache() *ResponseCache
+	TruncationRetries() int
+	// Err returns why the client can't be used, e.g. a mode it doesn't
+	// support, or nil. Requests made with such a client fail with it.
+	Err() error
 
 	// Chat / Messages
 
@@ -30,6 +39,34 @@ type Instructor interface {
 		schema interface{},
 	) (<-chan string, error)
 
+	modelName(request interface{}) string
+
+	// requestMode returns the mode of a synchronous or streaming request,
+	// which differs from Mode and StreamMode with ModeAuto for models
+	// supporting fewer modes than their provider.
+	requestMode(request interface{}, stream bool) Mode
+
+	// increaseMaxTokens returns a copy of request with a higher token limit,
+	// or false if the limit can't be raised.
+	increaseMaxTokens(request interface{}) (interface{}, bool)
+
+	// reask returns a follow-up request replying to the model's previous
+	// response with feedback on what was wrong with it.
+	reask(request interface{}, response string, feedback string) interface{}
+
+	// addToolResults returns a follow-up request with the model's tool calls
+	// and the results of running them, in the same order.
+	addToolResults(request interface{}, calls []parallelCall, results []toolResult) interface{}
+
+	// addUserMessage returns a copy of request with message as the next user
+	// turn.
+	addUserMessage(request interface{}, message string) interface{}
+
+	// addReply returns a copy of request with the model's reply in response
+	// recorded in its history. reply is the JSON of the parsed reply, used when
+	// response holds no content, e.g. when it was cached.
+	addReply(request interface{}, response interface{}, reply string) interface{}
+
 	// Usage counting
 
 	emptyResponseWithUsageSum(usage *UsageSum) interface{}
@@ -0,0 +1,245 @@
+package instructor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// iterable is the response type of CreateIterable: the items are asked for in
+// a single object, so every provider and mode can return many of them.
+type iterable[T any] struct {
+	Items []T `json:"items"`
+}
+
+// InstructorName names the tool after the item type, e.g. `PersonList`.
+func (iterable[T]) InstructorName() string {
+	return envelopeName(reflect.TypeOf([]T{}))
+}
+
+// CreateIterable extracts all instances of T found by a single, non-streaming
+// request. request is the provider request as passed to the client, e.g. an
+// openai.ChatCompletionRequest or a *cohere.ChatRequest, and the returned
+// response the matching provider response.
+//
+// Items are decoded and validated one by one. Valid items are kept, and while
+// retries are left the model is told which items were invalid and why, and
+// asked for corrected versions of those only. When retries run out, the valid
+// items are returned with the error, wrapping why the last invalid item was
+// rejected.
+func CreateIterable[T any](ctx context.Context, i Instructor, request interface{}) ([]T, interface{}, error) {
+	if err := i.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	schema, err := schemas.forProvider(i.Provider(), reflect.TypeOf(iterable[T]{}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if i.Validate() {
+		validate = validator.New()
+	}
+
+	// keep a running total of usage
+	usage := &UsageSum{}
+
+	var items []T
+
+	// why the last invalid item was rejected, returned once out of retries
+	var lastErr error
+
+	for attempt := 0; attempt <= i.MaxRetries(); attempt++ {
+
+		reservation, err := i.RateLimiter().wait(ctx, estimateTokens(request, schema))
+		if err != nil {
+			return items, i.emptyResponseWithUsageSum(usage), err
+		}
+
+		text, resp, err := i.chat(ctx, request, schema)
+		if err != nil {
+			return items, i.emptyResponseWithResponseUsage(resp), err
+		}
+
+		i.RateLimiter().record(reservation, i.countUsageFromResponse(resp, &UsageSum{}))
+
+		text = extractJSON(&text)
+
+		valid, feedback, err := parseItems[T](ctx, i, text)
+		items = append(items, valid...)
+
+		if err == nil {
+			response, err := i.addUsageSumToResponse(resp, usage)
+			return items, response, err
+		}
+
+		i.countUsageFromResponse(resp, usage)
+		lastErr = err
+
+		request = i.reask(request, text, feedback)
+	}
+
+	return items, i.emptyResponseWithUsageSum(usage), retriesExhausted(lastErr)
+}
+
+// parseItems decodes and validates the items in text, returning the valid ones
+// and, if any item is invalid, feedback on the invalid ones for the model with
+// the error of the last one.
+func parseItems[T any](ctx context.Context, i Instructor, text string) ([]T, string, error) {
+	raws, err := splitItems(text)
+	if err != nil {
+		return nil, fmt.Sprintf("Your response could not be parsed: %s. Respond again with all items.", err), err
+	}
+
+	var items []T
+	var problems []string
+	var lastErr error
+
+	for idx, raw := range raws {
+		var item T
+		if err := validateItem(ctx, i, raw, &item); err != nil {
+			problems = append(problems, fmt.Sprintf("- item %d %s: %s", idx+1, raw, err))
+			lastErr = fmt.Errorf("item %d: %w", idx+1, err)
+			continue
+		}
+		items = append(items, item)
+	}
+
+	if len(problems) == 0 {
+		return items, "", nil
+	}
+
+	return items, fmt.Sprintf(
+		"The following items were invalid and have been discarded:\n%s\n\nRespond again with corrected versions of only these items, the other items were kept.",
+		strings.Join(problems, "\n"),
+	), lastErr
+}
+
+// splitItems returns the items of an `{"items": [...]}` object, of a bare
+// array, or a single item.
+func splitItems(text string) ([]json.RawMessage, error) {
+	var wrapper struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(text), &wrapper); err == nil && wrapper.Items != nil {
+		return wrapper.Items, nil
+	}
+
+	var array []json.RawMessage
+	if err := json.Unmarshal([]byte(text), &array); err == nil {
+		return array, nil
+	}
+
+	var item json.RawMessage
+	if err := json.Unmarshal([]byte(text), &item); err != nil {
+		return nil, err
+	}
+	return []json.RawMessage{item}, nil
+}
+
+func validateItem(ctx context.Context, i Instructor, raw json.RawMessage, item any) error {
+	if err := decodeJSON(raw, item); err != nil {
+		return err
+	}
+	return checkItem(ctx, i, item)
+}
+
+// checkItem checks a decoded item against its enums, citations and, with
+// WithValidation, its validation tags.
+func checkItem(ctx context.Context, i Instructor, item any) error {
+	if err := validateEnums(item); err != nil {
+		return err
+	}
+
+	if source, ok := citationSourceFromContext(ctx); ok {
+		if err := verifyCitations(item, source); err != nil {
+			return err
+		}
+	}
+
+	if i.Validate() && derefType(reflect.TypeOf(item)).Kind() == reflect.Struct {
+		if err := validate.Struct(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toolCall is a call of a tool by the model, with its JSON arguments.
+type toolCall struct {
+	id        string
+	name      string
+	arguments string
+}
+
+// joinToolCalls returns the JSON to decode from the tool calls of a response:
+// the arguments of a single call, or an array of the arguments of all calls.
+// Parallel calls of an `{"items": [...]}` tool are merged into one object.
+func (s *Schema) joinToolCalls(calls []toolCall) (string, error) {
+	if len(calls) == 0 {
+		return "", errors.New("received no tool calls from model, expected at least 1")
+	}
+
+	if s.parallel != nil {
+		array := make([]parallelCall, len(calls))
+		for idx, call := range calls {
+			if !json.Valid([]byte(call.arguments)) {
+				return "", fmt.Errorf("tool call %s has invalid JSON arguments", call.name)
+			}
+			array[idx] = parallelCall{ID: call.id, Name: call.name, Arguments: json.RawMessage(call.arguments)}
+		}
+
+		b, err := json.Marshal(array)
+		return string(b), err
+	}
+
+	if len(calls) == 1 {
+		return s.toolArguments(calls[0].name, calls[0].arguments), nil
+	}
+
+	if s.itemsOnly() {
+		var merged struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		for _, call := range calls {
+			var items struct {
+				Items []json.RawMessage `json:"items"`
+			}
+			if err := json.Unmarshal([]byte(call.arguments), &items); err != nil {
+				return "", err
+			}
+			merged.Items = append(merged.Items, items.Items...)
+		}
+
+		b, err := json.Marshal(merged)
+		return string(b), err
+	}
+
+	array := make([]json.RawMessage, len(calls))
+	for idx, call := range calls {
+		arguments := s.toolArguments(call.name, call.arguments)
+		if !json.Valid([]byte(arguments)) {
+			return "", fmt.Errorf("tool call %s has invalid JSON arguments", call.name)
+		}
+		array[idx] = json.RawMessage(arguments)
+	}
+
+	b, err := json.Marshal(array)
+	return string(b), err
+}
+
+// itemsOnly reports whether the response object only holds an `items` array.
+func (s *Schema) itemsOnly() bool {
+	root := s.root()
+	if root.Properties == nil || root.Properties.Len() != 1 {
+		return false
+	}
+	items, ok := root.Properties.Get("items")
+	return ok && items.Type == "array"
+}
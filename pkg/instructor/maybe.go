@@ -0,0 +1,26 @@
+package instructor
+
+// Maybe wraps a response type for extractions where the data may not be in the
+// input. Instead of making something up to satisfy the schema, the model can
+// leave the result out and report why:
+//
+//	var maybe instructor.Maybe[Person]
+//	_, err := client.CreateChatCompletion(ctx, request, &maybe)
+//	person, found, reason := maybe.Unwrap()
+//
+// Validation and enum checks only apply to the result when it is present.
+type Maybe[T any] struct {
+	Result  *T     `json:"result,omitempty" jsonschema:"description=The extracted data or nothing if it is not present"`
+	Error   bool   `json:"error" jsonschema:"description=Whether the data could not be extracted"`
+	Message string `json:"message,omitempty" jsonschema:"description=Why the data could not be extracted"`
+}
+
+// Unwrap returns the result and whether it was found, or the zero value, false
+// and the model's reason otherwise.
+func (m Maybe[T]) Unwrap() (T, bool, string) {
+	if m.Error || m.Result == nil {
+		var zero T
+		return zero, false, m.Message
+	}
+	return *m.Result, true, ""
+}
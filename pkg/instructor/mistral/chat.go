@@ -0,0 +1,138 @@
+package mistral
+
+import "encoding/json"
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+const (
+	ToolChoiceAuto = "auto"
+	ToolChoiceNone = "none"
+	// ToolChoiceAny forces a call of one of the tools.
+	ToolChoiceAny = "any"
+)
+
+const (
+	FinishReasonStop        = "stop"
+	FinishReasonLength      = "length"
+	FinishReasonModelLength = "model_length"
+	FinishReasonToolCalls   = "tool_calls"
+	FinishReasonError       = "error"
+)
+
+const ResponseFormatJSONObject = "json_object"
+
+type ChatCompletionRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	RandomSeed  *int     `json:"random_seed,omitempty"`
+	SafePrompt  bool     `json:"safe_prompt,omitempty"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	Tools          []Tool          `json:"tools,omitempty"`
+	ToolChoice     string          `json:"tool_choice,omitempty"`
+
+	Stream bool `json:"stream"`
+}
+
+type ResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+	// Name is the name of the called tool, for tool messages.
+	Name string `json:"name,omitempty"`
+}
+
+type Tool struct {
+	Type     string   `json:"type"`
+	Function Function `json:"function"`
+}
+
+type Function struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters"`
+}
+
+type ToolCall struct {
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name string `json:"name"`
+	// Arguments is a JSON string.
+	Arguments string `json:"arguments"`
+}
+
+// UnmarshalJSON also accepts the arguments as a JSON object, as some
+// deployments return them.
+func (c *FunctionCall) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	c.Name = raw.Name
+	c.Arguments = ""
+
+	if len(raw.Arguments) > 0 && raw.Arguments[0] == '"' {
+		return json.Unmarshal(raw.Arguments, &c.Arguments)
+	}
+	if string(raw.Arguments) != "null" {
+		c.Arguments = string(raw.Arguments)
+	}
+	return nil
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type ChatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []ChatCompletionChoice `json:"choices"`
+	Usage   Usage                  `json:"usage"`
+}
+
+type ChatCompletionChoice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+type ChatCompletionStreamResponse struct {
+	ID      string                       `json:"id"`
+	Model   string                       `json:"model"`
+	Choices []ChatCompletionStreamChoice `json:"choices"`
+	Usage   *Usage                       `json:"usage,omitempty"`
+}
+
+type ChatCompletionStreamChoice struct {
+	Index        int     `json:"index"`
+	Delta        Message `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+}
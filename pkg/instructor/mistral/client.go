@@ -0,0 +1,192 @@
+// Package mistral is a minimal client for the Mistral AI chat completions API.
+// It differs from the OpenAI API in the values of `tool_choice` ("any" forces
+// a tool call) and in only supporting the `json_object` response format.
+package mistral
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const DefaultBaseURL = "https://api.mistral.ai/v1"
+
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+type ClientOption func(*Client)
+
+// WithBaseURL sets the API address, e.g. for a self-deployed model.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the HTTP client requests are sent with.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func NewClient(apiKey string, opts ...ClientOption) *Client {
+	c := &Client{
+		apiKey:     apiKey,
+		baseURL:    DefaultBaseURL,
+		httpClient: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.baseURL = strings.TrimRight(c.baseURL, "/")
+
+	return c
+}
+
+// APIError is an error response of the Mistral API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mistral: %s (status %d)", e.Message, e.StatusCode)
+}
+
+func (c *Client) CreateChatCompletion(ctx context.Context, request ChatCompletionRequest) (ChatCompletionResponse, error) {
+	request.Stream = false
+
+	body, err := c.post(ctx, "/chat/completions", request)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer body.Close()
+
+	var resp ChatCompletionResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return ChatCompletionResponse{}, err
+	}
+
+	return resp, nil
+}
+
+// CreateChatCompletionStream returns the server-sent chunks of a completion.
+// The last chunk holds the usage.
+func (c *Client) CreateChatCompletionStream(ctx context.Context, request ChatCompletionRequest) (*ChatCompletionStream, error) {
+	request.Stream = true
+
+	body, err := c.post(ctx, "/chat/completions", request)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	return &ChatCompletionStream{body: body, scanner: scanner}, nil
+}
+
+func (c *Client) post(ctx context.Context, path string, request any) (io.ReadCloser, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+func newAPIError(resp *http.Response) error {
+	b, _ := io.ReadAll(resp.Body)
+
+	// errors come as {"message": "..."} or, for validation errors, with a
+	// list of details
+	var body struct {
+		Message json.RawMessage `json:"message"`
+		Detail  json.RawMessage `json:"detail"`
+	}
+	message := strings.TrimSpace(string(b))
+	if err := json.Unmarshal(b, &body); err == nil {
+		var text string
+		switch {
+		case json.Unmarshal(body.Message, &text) == nil && text != "":
+			message = text
+		case len(body.Message) > 0:
+			message = string(body.Message)
+		case len(body.Detail) > 0:
+			message = string(body.Detail)
+		}
+	}
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// ChatCompletionStream reads the server-sent events of a streamed completion.
+type ChatCompletionStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// Recv returns the next chunk, or io.EOF once the stream is done.
+func (s *ChatCompletionStream) Recv() (*ChatCompletionStreamResponse, error) {
+	for s.scanner.Scan() {
+		line := bytes.TrimSpace(s.scanner.Bytes())
+
+		data, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			// blank lines separate events, other fields aren't used
+			continue
+		}
+		data = bytes.TrimSpace(data)
+
+		if string(data) == "[DONE]" {
+			return nil, io.EOF
+		}
+
+		var chunk ChatCompletionStreamResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return nil, err
+		}
+		return &chunk, nil
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (s *ChatCompletionStream) Close() error {
+	return s.body.Close()
+}
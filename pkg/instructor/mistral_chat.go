@@ -0,0 +1,311 @@
+package instructor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor/mistral"
+)
+
+func (i *InstructorMistral) CreateChatCompletion(
+	ctx context.Context,
+	request mistral.ChatCompletionRequest,
+	responseType any,
+) (response mistral.ChatCompletionResponse, err error) {
+
+	resp, err := chatHandler(i, ctx, request, responseType)
+	if err != nil {
+		if resp == nil {
+			return mistral.ChatCompletionResponse{}, err
+		}
+		return *nilMistralRespWithUsage(resp.(*mistral.ChatCompletionResponse)), err
+	}
+
+	response = *(resp.(*mistral.ChatCompletionResponse))
+
+	return response, nil
+}
+
+func (i *InstructorMistral) chat(ctx context.Context, request interface{}, schemaIn interface{}) (string, interface{}, error) {
+	schema := schemaIn.(*Schema)
+	req, ok := request.(mistral.ChatCompletionRequest)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid request type for %s client", i.Provider())
+	}
+
+	if req.Stream {
+		return "", nil, errors.New("streaming is not supported by this method; use CreateChatCompletionStream instead")
+	}
+
+	mode := i.requestMode(request, false)
+
+	switch mode {
+	case ModeToolCall:
+		return i.chatToolCall(ctx, &req, schema)
+	case ModeJSON:
+		return i.chatJSON(ctx, &req, schema)
+	default:
+		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorMistral) modelName(request interface{}) string {
+	req, ok := request.(mistral.ChatCompletionRequest)
+	if !ok {
+		return ""
+	}
+	return req.Model
+}
+
+func (i *InstructorMistral) increaseMaxTokens(request interface{}) (interface{}, bool) {
+	req, ok := request.(mistral.ChatCompletionRequest)
+	if !ok || req.MaxTokens == 0 {
+		return request, false
+	}
+	req.MaxTokens *= 2
+	return req, true
+}
+
+func (i *InstructorMistral) reask(request interface{}, response string, feedback string) interface{} {
+	req, ok := request.(mistral.ChatCompletionRequest)
+	if !ok {
+		return request
+	}
+
+	messages := make([]mistral.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages,
+		mistral.Message{Role: mistral.RoleAssistant, Content: response},
+		mistral.Message{Role: mistral.RoleUser, Content: feedback},
+	)
+
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorMistral) addUserMessage(request interface{}, message string) interface{} {
+	req, ok := request.(mistral.ChatCompletionRequest)
+	if !ok {
+		return request
+	}
+
+	messages := make([]mistral.Message, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, mistral.Message{Role: mistral.RoleUser, Content: message})
+
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorMistral) addReply(request interface{}, response interface{}, reply string) interface{} {
+	req, ok := request.(mistral.ChatCompletionRequest)
+	if !ok {
+		return request
+	}
+
+	message := mistral.Message{Role: mistral.RoleAssistant, Content: reply}
+	if resp, ok := response.(*mistral.ChatCompletionResponse); ok && len(resp.Choices) > 0 {
+		message = resp.Choices[0].Message
+	}
+
+	messages := make([]mistral.Message, 0, len(req.Messages)+1+len(message.ToolCalls))
+	messages = append(messages, req.Messages...)
+	messages = append(messages, message)
+	for _, call := range message.ToolCalls {
+		messages = append(messages, mistral.Message{
+			Role:       mistral.RoleTool,
+			Content:    recordedReply,
+			ToolCallID: call.ID,
+			Name:       call.Function.Name,
+		})
+	}
+
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorMistral) addToolResults(request interface{}, calls []parallelCall, results []toolResult) interface{} {
+	req, ok := request.(mistral.ChatCompletionRequest)
+	if !ok {
+		return request
+	}
+
+	assistant := mistral.Message{Role: mistral.RoleAssistant}
+	for _, call := range calls {
+		assistant.ToolCalls = append(assistant.ToolCalls, mistral.ToolCall{
+			ID:   call.ID,
+			Type: "function",
+			Function: mistral.FunctionCall{
+				Name:      call.Name,
+				Arguments: string(call.Arguments),
+			},
+		})
+	}
+
+	messages := make([]mistral.Message, 0, len(req.Messages)+1+len(results))
+	messages = append(messages, req.Messages...)
+	messages = append(messages, assistant)
+	for idx, result := range results {
+		messages = append(messages, mistral.Message{
+			Role:       mistral.RoleTool,
+			Content:    result.content,
+			ToolCallID: calls[idx].ID,
+			Name:       calls[idx].Name,
+		})
+	}
+
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorMistral) chatToolCall(ctx context.Context, request *mistral.ChatCompletionRequest, schema *Schema) (string, *mistral.ChatCompletionResponse, error) {
+
+	request.Tools = createMistralTools(schema)
+	request.ToolChoice = mistral.ToolChoiceAny
+
+	resp, err := i.Client.CreateChatCompletion(ctx, *request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := checkMistralResponse(&resp); err != nil {
+		return "", nilMistralRespWithUsage(&resp), err
+	}
+
+	message := resp.Choices[0].Message
+
+	calls := make([]toolCall, 0, len(message.ToolCalls))
+	for _, call := range message.ToolCalls {
+		calls = append(calls, toolCall{id: call.ID, name: call.Function.Name, arguments: call.Function.Arguments})
+	}
+
+	text, err := schema.joinToolCalls(calls)
+	if err != nil {
+		return "", nilMistralRespWithUsage(&resp), err
+	}
+
+	return text, &resp, nil
+}
+
+func (i *InstructorMistral) chatJSON(ctx context.Context, request *mistral.ChatCompletionRequest, schema *Schema) (string, *mistral.ChatCompletionResponse, error) {
+
+	request.Messages = prepend(request.Messages, createMistralJSONMessage(schema))
+	request.ResponseFormat = &mistral.ResponseFormat{Type: mistral.ResponseFormatJSONObject}
+
+	resp, err := i.Client.CreateChatCompletion(ctx, *request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := checkMistralResponse(&resp); err != nil {
+		return "", nilMistralRespWithUsage(&resp), err
+	}
+
+	return resp.Choices[0].Message.Content, &resp, nil
+}
+
+func createMistralTools(schema *Schema) []mistral.Tool {
+	tools := make([]mistral.Tool, 0, len(schema.Functions))
+	for _, function := range schema.Functions {
+		tools = append(tools, mistral.Tool{
+			Type: "function",
+			Function: mistral.Function{
+				Name:        function.Name,
+				Description: function.Description,
+				Parameters:  function.Parameters,
+			},
+		})
+	}
+	return tools
+}
+
+func createMistralJSONMessage(schema *Schema) mistral.Message {
+	message := fmt.Sprintf(`
+Please respond with JSON in the following JSON schema:
+
+%s
+
+Make sure to return an instance of the JSON, not the schema itself
+`, schema.String)
+
+	return mistral.Message{
+		Role:    mistral.RoleSystem,
+		Content: message,
+	}
+}
+
+func checkMistralResponse(resp *mistral.ChatCompletionResponse) error {
+	if len(resp.Choices) == 0 {
+		return errors.New("received no choices from model")
+	}
+
+	switch reason := resp.Choices[0].FinishReason; reason {
+	case mistral.FinishReasonLength, mistral.FinishReasonModelLength:
+		return &TruncationError{Provider: ProviderMistral, Reason: reason}
+	case mistral.FinishReasonError:
+		return fmt.Errorf("%s generation failed (%s)", ProviderMistral, reason)
+	}
+
+	return nil
+}
+
+func (i *InstructorMistral) emptyResponseWithUsageSum(usage *UsageSum) interface{} {
+	return &mistral.ChatCompletionResponse{
+		Usage: mistral.Usage{
+			PromptTokens:     usage.InputTokens,
+			CompletionTokens: usage.OutputTokens,
+			TotalTokens:      usage.TotalTokens,
+		},
+	}
+}
+
+func (i *InstructorMistral) emptyResponseWithResponseUsage(response interface{}) interface{} {
+	resp, ok := response.(*mistral.ChatCompletionResponse)
+	if !ok || resp == nil {
+		return nil
+	}
+
+	return &mistral.ChatCompletionResponse{
+		Usage: resp.Usage,
+	}
+}
+
+func (i *InstructorMistral) addUsageSumToResponse(response interface{}, usage *UsageSum) (interface{}, error) {
+	resp, ok := response.(*mistral.ChatCompletionResponse)
+	if !ok {
+		return response, fmt.Errorf("internal type error: expected *mistral.ChatCompletionResponse, got %T", response)
+	}
+	if resp == nil {
+		return i.emptyResponseWithUsageSum(usage), nil
+	}
+
+	resp.Usage.PromptTokens += usage.InputTokens
+	resp.Usage.CompletionTokens += usage.OutputTokens
+	resp.Usage.TotalTokens += usage.TotalTokens
+
+	return response, nil
+}
+
+func (i *InstructorMistral) countUsageFromResponse(response interface{}, usage *UsageSum) *UsageSum {
+	resp, ok := response.(*mistral.ChatCompletionResponse)
+	if !ok || resp == nil {
+		return usage
+	}
+
+	usage.InputTokens += resp.Usage.PromptTokens
+	usage.OutputTokens += resp.Usage.CompletionTokens
+	usage.TotalTokens += resp.Usage.TotalTokens
+
+	return usage
+}
+
+func nilMistralRespWithUsage(resp *mistral.ChatCompletionResponse) *mistral.ChatCompletionResponse {
+	if resp == nil {
+		return nil
+	}
+
+	return &mistral.ChatCompletionResponse{
+		Usage: resp.Usage,
+	}
+}
@@ -0,0 +1,104 @@
+package instructor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor/mistral"
+)
+
+func (i *InstructorMistral) CreateChatCompletionStream(
+	ctx context.Context,
+	request mistral.ChatCompletionRequest,
+	responseType any,
+) (stream <-chan any, err error) {
+
+	stream, err = chatStreamHandler(i, ctx, request, responseType)
+	if err != nil {
+		return nil, err
+	}
+
+	return stream, err
+}
+
+func (i *InstructorMistral) chatStream(ctx context.Context, request interface{}, schemaIn interface{}) (<-chan string, error) {
+	schema := schemaIn.(*Schema)
+	req, ok := request.(mistral.ChatCompletionRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type for %s client", i.Provider())
+	}
+
+	mode := i.requestMode(request, true)
+
+	switch mode {
+	case ModeToolCall:
+		return i.chatToolCallStream(ctx, &req, schema)
+	case ModeJSON:
+		return i.chatJSONStream(ctx, &req, schema)
+	default:
+		return nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorMistral) chatToolCallStream(ctx context.Context, request *mistral.ChatCompletionRequest, schema *Schema) (<-chan string, error) {
+	request.Tools = createMistralTools(schema)
+	request.ToolChoice = mistral.ToolChoiceAny
+	return i.createStream(ctx, request)
+}
+
+func (i *InstructorMistral) chatJSONStream(ctx context.Context, request *mistral.ChatCompletionRequest, schema *Schema) (<-chan string, error) {
+	request.Messages = prepend(request.Messages, createMistralJSONMessage(schema))
+	request.ResponseFormat = &mistral.ResponseFormat{Type: mistral.ResponseFormatJSONObject}
+	return i.createStream(ctx, request)
+}
+
+// createStream sends the content of every chunk, and the arguments of tool
+// calls, which Mistral streams whole rather than in pieces.
+func (i *InstructorMistral) createStream(ctx context.Context, request *mistral.ChatCompletionRequest) (<-chan string, error) {
+	stream, err := i.Client.CreateChatCompletionStream(ctx, *request)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan string)
+
+	go func() {
+		defer stream.Close()
+		defer close(ch)
+		for {
+			response, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				return
+			}
+			if response.Usage != nil {
+				recordStreamUsage(ctx, i.countUsageFromResponse(&mistral.ChatCompletionResponse{Usage: *response.Usage}, &UsageSum{}))
+			}
+			if len(response.Choices) == 0 {
+				continue
+			}
+
+			delta := response.Choices[0].Delta
+			texts := []string{delta.Content}
+			for _, call := range delta.ToolCalls {
+				texts = append(texts, call.Function.Arguments)
+			}
+
+			for _, text := range texts {
+				if text == "" {
+					continue
+				}
+				select {
+				case ch <- text:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
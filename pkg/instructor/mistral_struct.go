@@ -0,0 +1,80 @@
+package instructor
+
+import (
+	"github.com/binarycraft007/instructor-go/pkg/instructor/mistral"
+)
+
+type InstructorMistral struct {
+	*mistral.Client
+
+	provider   Provider
+	mode       Mode
+	streamMode Mode
+	// auto is set if the modes were picked by ModeAuto
+	auto       bool
+	maxRetries int
+	validate   bool
+
+	rateLimiter       *RateLimiter
+	cache             *ResponseCache
+	truncationRetries int
+
+	// err is why the client can't be used, e.g. an unsupported mode
+	err error
+}
+
+var _ Instructor = &InstructorMistral{}
+
+func FromMistral(client *mistral.Client, opts ...Options) *InstructorMistral {
+
+	options := mergeOptions(opts...)
+
+	i := &InstructorMistral{
+		Client: client,
+
+		provider:   ProviderMistral,
+		maxRetries: *options.MaxRetries,
+		validate:   *options.validate,
+
+		rateLimiter:       options.rateLimiter,
+		cache:             options.cache,
+		truncationRetries: *options.truncationRetries,
+	}
+	i.mode, i.streamMode, i.auto, i.err = resolveModes(i.provider, ProviderModes(i.provider), options.Mode)
+
+	return i
+}
+
+func (i *InstructorMistral) Provider() Provider {
+	return i.provider
+}
+func (i *InstructorMistral) Mode() Mode {
+	return i.mode
+}
+func (i *InstructorMistral) StreamMode() Mode {
+	return i.streamMode
+}
+
+// requestMode returns the mode of a synchronous or streaming request, which
+// with ModeAuto is the best mode the model of the request supports.
+func (i *InstructorMistral) requestMode(request interface{}, stream bool) Mode {
+	return modelMode(i, i.auto, ProviderModes(i.provider), request, stream)
+}
+func (i *InstructorMistral) MaxRetries() int {
+	return i.maxRetries
+}
+func (i *InstructorMistral) Validate() bool {
+	return i.validate
+}
+func (i *InstructorMistral) RateLimiter() *RateLimiter {
+	return i.rateLimiter
+}
+func (i *InstructorMistral) Cache() *ResponseCache {
+	return i.cache
+}
+func (i *InstructorMistral) TruncationRetries() int {
+	return i.truncationRetries
+}
+func (i *InstructorMistral) Err() error {
+	return i.err
+}
@@ -9,5 +9,15 @@ const (
 	ModeJSONStrict     Mode = "json_strict_mode"
 	ModeJSONSchema     Mode = "json_schema_mode"
 	ModeMarkdownJSON   Mode = "markdown_json_mode"
-	ModeDefault        Mode = ModeJSONSchema
+	// ModeStructuredOutputs sends the response schema as a strict OpenAI
+	// `json_schema` response format (structured outputs).
+	ModeStructuredOutputs Mode = "structured_outputs_mode"
+	// ModeGrammar constrains the output to a GBNF grammar generated from the
+	// response schema, on OpenAI-compatible servers like llama.cpp.
+	ModeGrammar Mode = "grammar_mode"
+	// ModeAuto picks the best mode the client supports, separately for
+	// synchronous and streaming calls, and for the model of each request. See
+	// ProviderModes and ModelModes.
+	ModeAuto    Mode = "auto_mode"
+	ModeDefault Mode = ModeJSONSchema
 )
@@ -0,0 +1,69 @@
+package ollama
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// FormatJSON asks for any valid JSON, without a schema.
+var FormatJSON = json.RawMessage(`"json"`)
+
+type ChatRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+
+	// Format is FormatJSON or a JSON schema the response is constrained to.
+	Format json.RawMessage `json:"format,omitempty"`
+
+	// Options are model parameters such as `num_ctx`, `num_predict` or
+	// `temperature`.
+	Options map[string]any `json:"options,omitempty"`
+
+	// KeepAlive is how long the model stays loaded after the request, e.g.
+	// "10m", or "0" to unload it right away.
+	KeepAlive string `json:"keep_alive,omitempty"`
+
+	Stream *bool `json:"stream,omitempty"`
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+
+	// Images are base64 encoded, for multimodal models.
+	Images    []string   `json:"images,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+type ToolCall struct {
+	Function ToolCallFunction `json:"function"`
+}
+
+type ToolCallFunction struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+type ChatResponse struct {
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"created_at"`
+	Message   Message   `json:"message"`
+
+	Done bool `json:"done"`
+	// DoneReason is "stop", or "length" when `num_predict` was reached.
+	DoneReason string `json:"done_reason,omitempty"`
+
+	TotalDuration      time.Duration `json:"total_duration,omitempty"`
+	LoadDuration       time.Duration `json:"load_duration,omitempty"`
+	PromptEvalCount    int           `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration time.Duration `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int           `json:"eval_count,omitempty"`
+	EvalDuration       time.Duration `json:"eval_duration,omitempty"`
+}
@@ -0,0 +1,192 @@
+// Package ollama is a minimal client for the native Ollama chat API, which
+// unlike its OpenAI compatible endpoint accepts a JSON schema as `format` for
+// constrained decoding, `keep_alive` and model options such as `num_ctx`.
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultBaseURL is used when neither WithBaseURL nor OLLAMA_HOST is set.
+const DefaultBaseURL = "http://localhost:11434"
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+type ClientOption func(*Client)
+
+// WithBaseURL sets the address of the Ollama server, e.g. `http://host:11434`.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithHTTPClient sets the HTTP client requests are sent with.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient returns a client for the server at OLLAMA_HOST, or the local
+// default, unless WithBaseURL is given.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		httpClient: http.DefaultClient,
+	}
+
+	if host := os.Getenv("OLLAMA_HOST"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		c.baseURL = host
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.baseURL = strings.TrimRight(c.baseURL, "/")
+
+	return c
+}
+
+// APIError is an error response of the Ollama server.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ollama: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// Chat sends a chat request and returns the complete response.
+func (c *Client) Chat(ctx context.Context, request *ChatRequest) (*ChatResponse, error) {
+	req := *request
+	req.Stream = new(bool)
+
+	body, err := c.post(ctx, "/api/chat", &req)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var resp ChatResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// ChatStream sends a chat request and returns the stream of partial responses.
+// The last one has Done set and holds the usage.
+func (c *Client) ChatStream(ctx context.Context, request *ChatRequest) (*ChatStream, error) {
+	req := *request
+	stream := true
+	req.Stream = &stream
+
+	body, err := c.post(ctx, "/api/chat", &req)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	return &ChatStream{body: body, scanner: scanner}, nil
+}
+
+func (c *Client) post(ctx context.Context, path string, request any) (io.ReadCloser, error) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+func newAPIError(resp *http.Response) error {
+	b, _ := io.ReadAll(resp.Body)
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil || body.Error == "" {
+		body.Error = strings.TrimSpace(string(b))
+	}
+	if body.Error == "" {
+		body.Error = http.StatusText(resp.StatusCode)
+	}
+
+	return &APIError{StatusCode: resp.StatusCode, Message: body.Error}
+}
+
+// ChatStream reads the newline delimited JSON responses of a streamed chat.
+type ChatStream struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// Recv returns the next partial response, or io.EOF after the last one.
+func (s *ChatStream) Recv() (*ChatResponse, error) {
+	for s.scanner.Scan() {
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var resp struct {
+			ChatResponse
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return nil, err
+		}
+		if resp.Error != "" {
+			return nil, errors.New("ollama: " + resp.Error)
+		}
+
+		return &resp.ChatResponse, nil
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (s *ChatStream) Close() error {
+	return s.body.Close()
+}
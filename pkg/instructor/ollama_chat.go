@@ -0,0 +1,262 @@
+package instructor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor/ollama"
+)
+
+func (i *InstructorOllama) Chat(
+	ctx context.Context,
+	request *ollama.ChatRequest,
+	response any,
+) (*ollama.ChatResponse, error) {
+
+	resp, err := chatHandler(i, ctx, request, response)
+	if err != nil {
+		if resp == nil {
+			return &ollama.ChatResponse{}, err
+		}
+		return nilOllamaRespWithUsage(resp.(*ollama.ChatResponse)), err
+	}
+
+	return resp.(*ollama.ChatResponse), nil
+}
+
+func (i *InstructorOllama) chat(ctx context.Context, request interface{}, schemaIn interface{}) (string, interface{}, error) {
+	schema := schemaIn.(*Schema)
+	req, ok := request.(*ollama.ChatRequest)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid request type for %s client", i.Provider())
+	}
+
+	if req.Stream != nil && *req.Stream {
+		return "", nil, errors.New("streaming is not supported by this method; use ChatStream instead")
+	}
+
+	// copy so the caller's request is left untouched
+	copied := *req
+
+	mode := i.requestMode(request, false)
+
+	switch mode {
+	case ModeJSON:
+		return i.chatJSON(ctx, &copied, schema, false)
+	case ModeJSONSchema:
+		return i.chatJSON(ctx, &copied, schema, true)
+	default:
+		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorOllama) modelName(request interface{}) string {
+	req, ok := request.(*ollama.ChatRequest)
+	if !ok {
+		return ""
+	}
+	return req.Model
+}
+
+func (i *InstructorOllama) increaseMaxTokens(request interface{}) (interface{}, bool) {
+	req, ok := request.(*ollama.ChatRequest)
+	if !ok {
+		return request, false
+	}
+
+	var limit float64
+	switch n := req.Options["num_predict"].(type) {
+	case int:
+		limit = float64(n)
+	case float64:
+		limit = n
+	}
+	if limit <= 0 {
+		return request, false
+	}
+
+	// copy so the caller's request is left untouched
+	increased := *req
+	increased.Options = make(map[string]any, len(req.Options))
+	for k, v := range req.Options {
+		increased.Options[k] = v
+	}
+	increased.Options["num_predict"] = int(limit * 2)
+	return &increased, true
+}
+
+func (i *InstructorOllama) reask(request interface{}, response string, feedback string) interface{} {
+	req, ok := request.(*ollama.ChatRequest)
+	if !ok {
+		return request
+	}
+
+	messages := make([]ollama.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages,
+		ollama.Message{Role: ollama.RoleAssistant, Content: response},
+		ollama.Message{Role: ollama.RoleUser, Content: feedback},
+	)
+
+	// copy so the caller's request is left untouched
+	reasked := *req
+	reasked.Messages = messages
+	return &reasked
+}
+
+// addToolResults is not supported, RunAgent rejects Ollama clients.
+func (i *InstructorOllama) addToolResults(request interface{}, calls []parallelCall, results []toolResult) interface{} {
+	return request
+}
+
+func (i *InstructorOllama) addUserMessage(request interface{}, message string) interface{} {
+	req, ok := request.(*ollama.ChatRequest)
+	if !ok {
+		return request
+	}
+
+	messages := make([]ollama.Message, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, ollama.Message{Role: ollama.RoleUser, Content: message})
+
+	// copy so the caller's request is left untouched
+	added := *req
+	added.Messages = messages
+	return &added
+}
+
+func (i *InstructorOllama) addReply(request interface{}, response interface{}, reply string) interface{} {
+	req, ok := request.(*ollama.ChatRequest)
+	if !ok {
+		return request
+	}
+
+	if resp, ok := response.(*ollama.ChatResponse); ok && resp.Message.Content != "" {
+		reply = resp.Message.Content
+	}
+
+	messages := make([]ollama.Message, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, ollama.Message{Role: ollama.RoleAssistant, Content: reply})
+
+	// copy so the caller's request is left untouched
+	added := *req
+	added.Messages = messages
+	return &added
+}
+
+// chatJSON asks for JSON following the schema, which with constrained set is
+// also enforced by passing it as `format`.
+func (i *InstructorOllama) chatJSON(ctx context.Context, request *ollama.ChatRequest, schema *Schema, constrained bool) (string, *ollama.ChatResponse, error) {
+
+	if err := setOllamaFormat(request, schema, constrained); err != nil {
+		return "", nil, err
+	}
+
+	resp, err := i.Client.Chat(ctx, request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := checkOllamaResponse(resp); err != nil {
+		return "", nilOllamaRespWithUsage(resp), err
+	}
+
+	return resp.Message.Content, resp, nil
+}
+
+func setOllamaFormat(request *ollama.ChatRequest, schema *Schema, constrained bool) error {
+	request.Messages = prepend(request.Messages, createOllamaJSONMessage(schema))
+
+	if !constrained {
+		request.Format = ollama.FormatJSON
+		return nil
+	}
+
+	format, err := json.Marshal(schema.Schema)
+	if err != nil {
+		return err
+	}
+	request.Format = format
+	return nil
+}
+
+func createOllamaJSONMessage(schema *Schema) ollama.Message {
+	message := fmt.Sprintf(`
+Please respond with JSON in the following JSON schema:
+
+%s
+
+Make sure to return an instance of the JSON, not the schema itself
+`, schema.String)
+
+	return ollama.Message{
+		Role:    ollama.RoleSystem,
+		Content: message,
+	}
+}
+
+func checkOllamaResponse(resp *ollama.ChatResponse) error {
+	if resp.DoneReason == "length" {
+		return &TruncationError{Provider: ProviderOllama, Reason: resp.DoneReason}
+	}
+	return nil
+}
+
+func (i *InstructorOllama) emptyResponseWithUsageSum(usage *UsageSum) interface{} {
+	return &ollama.ChatResponse{
+		PromptEvalCount: usage.InputTokens,
+		EvalCount:       usage.OutputTokens,
+	}
+}
+
+func (i *InstructorOllama) emptyResponseWithResponseUsage(response interface{}) interface{} {
+	resp, ok := response.(*ollama.ChatResponse)
+	if !ok || resp == nil {
+		return nil
+	}
+
+	return nilOllamaRespWithUsage(resp)
+}
+
+func (i *InstructorOllama) addUsageSumToResponse(response interface{}, usage *UsageSum) (interface{}, error) {
+	resp, ok := response.(*ollama.ChatResponse)
+	if !ok {
+		return response, fmt.Errorf("internal type error: expected *ollama.ChatResponse, got %T", response)
+	}
+	if resp == nil {
+		return i.emptyResponseWithUsageSum(usage), nil
+	}
+
+	resp.PromptEvalCount += usage.InputTokens
+	resp.EvalCount += usage.OutputTokens
+
+	return response, nil
+}
+
+func (i *InstructorOllama) countUsageFromResponse(response interface{}, usage *UsageSum) *UsageSum {
+	resp, ok := response.(*ollama.ChatResponse)
+	if !ok || resp == nil {
+		return usage
+	}
+
+	usage.InputTokens += resp.PromptEvalCount
+	usage.OutputTokens += resp.EvalCount
+	usage.TotalTokens += resp.PromptEvalCount + resp.EvalCount
+
+	return usage
+}
+
+func nilOllamaRespWithUsage(resp *ollama.ChatResponse) *ollama.ChatResponse {
+	if resp == nil {
+		return nil
+	}
+
+	return &ollama.ChatResponse{
+		Model:           resp.Model,
+		PromptEvalCount: resp.PromptEvalCount,
+		EvalCount:       resp.EvalCount,
+	}
+}
@@ -0,0 +1,88 @@
+package instructor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor/ollama"
+)
+
+func (i *InstructorOllama) ChatStream(
+	ctx context.Context,
+	request *ollama.ChatRequest,
+	responseType any,
+) (<-chan any, error) {
+
+	stream, err := chatStreamHandler(i, ctx, request, responseType)
+	if err != nil {
+		return nil, err
+	}
+
+	return stream, err
+}
+
+func (i *InstructorOllama) chatStream(ctx context.Context, request interface{}, schemaIn interface{}) (<-chan string, error) {
+	schema := schemaIn.(*Schema)
+	req, ok := request.(*ollama.ChatRequest)
+	if !ok {
+		return nil, fmt.Errorf("invalid request type for %s client", i.Provider())
+	}
+
+	// copy so the caller's request is left untouched
+	copied := *req
+
+	mode := i.requestMode(request, true)
+
+	switch mode {
+	case ModeJSON:
+		return i.chatJSONStream(ctx, &copied, schema, false)
+	case ModeJSONSchema:
+		return i.chatJSONStream(ctx, &copied, schema, true)
+	default:
+		return nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
+	}
+}
+
+func (i *InstructorOllama) chatJSONStream(ctx context.Context, request *ollama.ChatRequest, schema *Schema, constrained bool) (<-chan string, error) {
+	if err := setOllamaFormat(request, schema, constrained); err != nil {
+		return nil, err
+	}
+	return i.createStream(ctx, request)
+}
+
+func (i *InstructorOllama) createStream(ctx context.Context, request *ollama.ChatRequest) (<-chan string, error) {
+	stream, err := i.Client.ChatStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan string)
+
+	go func() {
+		defer stream.Close()
+		defer close(ch)
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				return
+			}
+			if resp.Message.Content != "" {
+				select {
+				case ch <- resp.Message.Content:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if resp.Done {
+				recordStreamUsage(ctx, i.countUsageFromResponse(resp, &UsageSum{}))
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
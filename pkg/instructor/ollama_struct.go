@@ -0,0 +1,80 @@
+package instructor
+
+import (
+	"github.com/binarycraft007/instructor-go/pkg/instructor/ollama"
+)
+
+type InstructorOllama struct {
+	*ollama.Client
+
+	provider   Provider
+	mode       Mode
+	streamMode Mode
+	// auto is set if the modes were picked by ModeAuto
+	auto       bool
+	maxRetries int
+	validate   bool
+
+	rateLimiter       *RateLimiter
+	cache             *ResponseCache
+	truncationRetries int
+
+	// err is why the client can't be used, e.g. an unsupported mode
+	err error
+}
+
+var _ Instructor = &InstructorOllama{}
+
+func FromOllama(client *ollama.Client, opts ...Options) *InstructorOllama {
+
+	options := mergeOptions(opts...)
+
+	i := &InstructorOllama{
+		Client: client,
+
+		provider:   ProviderOllama,
+		maxRetries: *options.MaxRetries,
+		validate:   *options.validate,
+
+		rateLimiter:       options.rateLimiter,
+		cache:             options.cache,
+		truncationRetries: *options.truncationRetries,
+	}
+	i.mode, i.streamMode, i.auto, i.err = resolveModes(i.provider, ProviderModes(i.provider), options.Mode)
+
+	return i
+}
+
+func (i *InstructorOllama) Provider() Provider {
+	return i.provider
+}
+func (i *InstructorOllama) Mode() Mode {
+	return i.mode
+}
+func (i *InstructorOllama) StreamMode() Mode {
+	return i.streamMode
+}
+
+// requestMode returns the mode of a synchronous or streaming request, which
+// with ModeAuto is the best mode the model of the request supports.
+func (i *InstructorOllama) requestMode(request interface{}, stream bool) Mode {
+	return modelMode(i, i.auto, ProviderModes(i.provider), request, stream)
+}
+func (i *InstructorOllama) MaxRetries() int {
+	return i.maxRetries
+}
+func (i *InstructorOllama) Validate() bool {
+	return i.validate
+}
+func (i *InstructorOllama) RateLimiter() *RateLimiter {
+	return i.rateLimiter
+}
+func (i *InstructorOllama) Cache() *ResponseCache {
+	return i.cache
+}
+func (i *InstructorOllama) TruncationRetries() int {
+	return i.truncationRetries
+}
+func (i *InstructorOllama) Err() error {
+	return i.err
+}
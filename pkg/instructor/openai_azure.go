@@ -0,0 +1,142 @@
+package instructor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI api-version used when
+// AzureConfig.APIVersion is empty, the first to support every mode.
+const DefaultAzureAPIVersion = "2024-08-01-preview"
+
+// AzureConfig configures a client for an Azure OpenAI resource.
+type AzureConfig struct {
+	// Endpoint of the resource, e.g. https://my-resource.openai.azure.com
+	Endpoint string
+	// APIVersion of the Azure OpenAI API, DefaultAzureAPIVersion if empty.
+	APIVersion string
+
+	// Deployments maps the model of a request to the name of its deployment.
+	// Models without an entry are sent to the deployment of the same name.
+	Deployments map[string]string
+
+	// APIKey of the resource, used unless Token is set.
+	APIKey string
+	// Token returns a Microsoft Entra ID access token for the
+	// https://cognitiveservices.azure.com/.default scope. It's called for
+	// every request, so it should cache and refresh tokens itself, like the
+	// credentials of azidentity do.
+	Token func(ctx context.Context) (string, error)
+
+	// HTTPClient sends the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// azureFeatures is the first api-version supporting each mode, modes missing
+// are supported by every version.
+var azureFeatures = map[Mode]string{
+	ModeToolCall:          "2023-12-01-preview",
+	ModeToolCallStrict:    "2024-08-01-preview",
+	ModeJSON:              "2023-12-01-preview",
+	ModeJSONStrict:        "2024-08-01-preview",
+	ModeStructuredOutputs: "2024-08-01-preview",
+}
+
+// FromAzureOpenAI returns an OpenAI client for the deployments of an Azure
+// OpenAI resource. The model of a request names the model, as for OpenAI, and
+// is mapped to its deployment by the config.
+//
+// A mode the configured api-version doesn't support (e.g. strict json_schema
+// before 2024-08-01-preview) is reported by Err.
+func FromAzureOpenAI(config AzureConfig, opts ...Options) *InstructorOpenAI {
+	apiKey := config.APIKey
+	if config.Token != nil {
+		apiKey = ""
+	}
+
+	clientConfig := openai.DefaultAzureConfig(apiKey, config.Endpoint)
+
+	clientConfig.APIVersion = config.APIVersion
+	if clientConfig.APIVersion == "" {
+		clientConfig.APIVersion = DefaultAzureAPIVersion
+	}
+
+	clientConfig.AzureModelMapperFunc = func(model string) string {
+		if deployment, ok := config.Deployments[model]; ok {
+			return deployment
+		}
+		return model
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	if config.Token != nil {
+		// go-openai only sends a static token, set a fresh one on every request instead
+		clientConfig.APIType = openai.APITypeAzureAD
+
+		copied := *httpClient
+		copied.Transport = &azureTokenTransport{token: config.Token, base: httpClient.Transport}
+		httpClient = &copied
+	}
+
+	clientConfig.HTTPClient = httpClient
+
+	opts = append(opts, Options{
+		azureAPIVersion: toPtr(clientConfig.APIVersion),
+		endpoint:        toPtr(config.Endpoint),
+	})
+
+	return FromOpenAI(openai.NewClientWithConfig(clientConfig), opts...)
+}
+
+// checkAzureMode returns an error if the client is for Azure OpenAI and its
+// api-version doesn't support mode.
+func (i *InstructorOpenAI) checkAzureMode(mode Mode) error {
+	if i.azureAPIVersion == "" {
+		return nil
+	}
+
+	since, ok := azureFeatures[mode]
+	if !ok || azureVersionDate(i.azureAPIVersion) >= azureVersionDate(since) {
+		return nil
+	}
+
+	return fmt.Errorf("mode '%s' requires Azure OpenAI api-version %s or later, got %s", mode, since, i.azureAPIVersion)
+}
+
+// azureVersionDate returns the date of an api-version like 2024-08-01-preview,
+// which orders previews along with the GA versions.
+func azureVersionDate(version string) string {
+	date, _, _ := strings.Cut(version, "-preview")
+	return date
+}
+
+// azureTokenTransport authenticates requests with a Microsoft Entra ID token.
+type azureTokenTransport struct {
+	token func(ctx context.Context) (string, error)
+	base  http.RoundTripper
+}
+
+func (t *azureTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("getting Entra ID token: %w", err)
+	}
+
+	// copy as round trippers must not modify the request
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
@@ -48,7 +48,14 @@ func (i *InstructorOpenAI) chat(ctx context.Context, request interface{}, schema
 		return "", nil, errors.New("streaming is not supported by this method; use CreateChatCompletionStream instead")
 	}
 
-	switch i.Mode() {
+	mode := i.requestMode(request, false)
+
+	ctx, err := i.withExtraBody(ctx, mode, schema)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch mode {
 	case ModeToolCall:
 		return i.chatToolCall(ctx, &req, schema, false)
 	case ModeToolCallStrict:
@@ -57,60 +64,159 @@ func (i *InstructorOpenAI) chat(ctx context.Context, request interface{}, schema
 		return i.chatJSON(ctx, &req, schema, false)
 	case ModeJSONStrict:
 		return i.chatJSON(ctx, &req, schema, true)
-	case ModeJSONSchema:
+	case ModeJSONSchema, ModeGrammar:
 		return i.chatJSONSchema(ctx, &req, schema)
+	case ModeStructuredOutputs:
+		return i.chatStructuredOutputs(ctx, &req, schema)
 	default:
-		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", i.Mode(), i.Provider())
+		return "", nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
 	}
 }
 
-func (i *InstructorOpenAI) chatToolCall(ctx context.Context, request *openai.ChatCompletionRequest, schema *Schema, strict bool) (string, *openai.ChatCompletionResponse, error) {
+func (i *InstructorOpenAI) modelName(request interface{}) string {
+	req, ok := request.(openai.ChatCompletionRequest)
+	if !ok {
+		return ""
+	}
+	return req.Model
+}
 
-	request.Tools = createOpenAITools(schema, strict)
+func (i *InstructorOpenAI) increaseMaxTokens(request interface{}) (interface{}, bool) {
+	req, ok := request.(openai.ChatCompletionRequest)
+	if !ok || req.MaxTokens == 0 {
+		// without an explicit limit the model's maximum was hit
+		return request, false
+	}
+	req.MaxTokens *= 2
+	return req, true
+}
 
-	resp, err := i.Client.CreateChatCompletion(ctx, *request)
-	if err != nil {
-		return "", nil, err
+func (i *InstructorOpenAI) reask(request interface{}, response string, feedback string) interface{} {
+	req, ok := request.(openai.ChatCompletionRequest)
+	if !ok {
+		return request
 	}
 
-	var toolCalls []openai.ToolCall
-	for _, choice := range resp.Choices {
-		toolCalls = choice.Message.ToolCalls
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages,
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: response},
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: feedback},
+	)
 
-		if len(toolCalls) >= 1 {
-			break
-		}
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorOpenAI) addUserMessage(request interface{}, message string) interface{} {
+	req, ok := request.(openai.ChatCompletionRequest)
+	if !ok {
+		return request
 	}
 
-	numTools := len(toolCalls)
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: message})
+
+	req.Messages = messages
+	return req
+}
 
-	if numTools < 1 {
-		return "", nilOpenaiRespWithUsage(&resp), errors.New("received no tool calls from model, expected at least 1")
+func (i *InstructorOpenAI) addReply(request interface{}, response interface{}, reply string) interface{} {
+	req, ok := request.(openai.ChatCompletionRequest)
+	if !ok {
+		return request
 	}
 
-	if numTools == 1 {
-		return toolCalls[0].Function.Arguments, &resp, nil
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: reply}
+	if resp, ok := response.(*openai.ChatCompletionResponse); ok && len(resp.Choices) > 0 {
+		message = resp.Choices[0].Message
 	}
 
-	// numTools >= 1
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages)+1+len(message.ToolCalls))
+	messages = append(messages, req.Messages...)
+	messages = append(messages, message)
+	for _, call := range message.ToolCalls {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:       openai.ChatMessageRoleTool,
+			Content:    recordedReply,
+			ToolCallID: call.ID,
+		})
+	}
 
-	jsonArray := make([]map[string]interface{}, len(toolCalls))
+	req.Messages = messages
+	return req
+}
 
-	for i, toolCall := range toolCalls {
-		var jsonObj map[string]interface{}
-		err = json.Unmarshal([]byte(toolCall.Function.Arguments), &jsonObj)
-		if err != nil {
-			return "", nilOpenaiRespWithUsage(&resp), err
+func (i *InstructorOpenAI) addToolResults(request interface{}, calls []parallelCall, results []toolResult) interface{} {
+	req, ok := request.(openai.ChatCompletionRequest)
+	if !ok {
+		return request
+	}
+
+	assistant := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+	for _, call := range calls {
+		assistant.ToolCalls = append(assistant.ToolCalls, openai.ToolCall{
+			ID:   call.ID,
+			Type: openai.ToolTypeFunction,
+			Function: openai.FunctionCall{
+				Name:      call.Name,
+				Arguments: string(call.Arguments),
+			},
+		})
+	}
+
+	messages := make([]openai.ChatCompletionMessage, 0, len(req.Messages)+1+len(results))
+	messages = append(messages, req.Messages...)
+	messages = append(messages, assistant)
+	for idx, result := range results {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:       openai.ChatMessageRoleTool,
+			Content:    result.content,
+			ToolCallID: calls[idx].ID,
+		})
+	}
+
+	req.Messages = messages
+	return req
+}
+
+func (i *InstructorOpenAI) chatToolCall(ctx context.Context, request *openai.ChatCompletionRequest, schema *Schema, strict bool) (string, *openai.ChatCompletionResponse, error) {
+
+	tools, err := createOpenAITools(schema, strict)
+	if err != nil {
+		return "", nil, err
+	}
+
+	request.Tools = tools
+	request.ToolChoice = createOpenAIToolChoice(schema)
+
+	resp, err := i.Client.CreateChatCompletion(ctx, *request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := checkOpenAIResponse(&resp); err != nil {
+		return "", nilOpenaiRespWithUsage(&resp), err
+	}
+
+	var calls []toolCall
+	for _, choice := range resp.Choices {
+		for _, call := range choice.Message.ToolCalls {
+			calls = append(calls, toolCall{id: call.ID, name: call.Function.Name, arguments: call.Function.Arguments})
+		}
+
+		if len(calls) >= 1 {
+			break
 		}
-		jsonArray[i] = jsonObj
 	}
 
-	resultJSON, err := json.Marshal(jsonArray)
+	text, err := schema.joinToolCalls(calls)
 	if err != nil {
 		return "", nilOpenaiRespWithUsage(&resp), err
 	}
 
-	return string(resultJSON), &resp, nil
+	return text, &resp, nil
 }
 
 func (i *InstructorOpenAI) chatJSON(ctx context.Context, request *openai.ChatCompletionRequest, schema *Schema, strict bool) (string, *openai.ChatCompletionResponse, error) {
@@ -120,12 +226,22 @@ func (i *InstructorOpenAI) chatJSON(ctx context.Context, request *openai.ChatCom
 	request.Messages = prepend(request.Messages, *createJSONMessage(schema))
 
 	if strict {
+		strictSchema, _, err := schema.Strict()
+		if err != nil {
+			return "", nil, err
+		}
+
+		strictRoot := strictSchema
+		if _, def := rootDefinition(strictSchema); def != nil {
+			strictRoot = def
+		}
+
 		schemaWrapper := ResponseFormatSchemaWrapper{
 			Type:        "object",
 			Required:    []string{structName},
-			Definitions: &schema.Schema.Definitions,
+			Definitions: &strictSchema.Definitions,
 			Properties: &jsonschema.Definitions{
-				structName: schema.Definitions[structName],
+				structName: strictRoot,
 			},
 			AdditionalProperties: false,
 		}
@@ -153,14 +269,43 @@ func (i *InstructorOpenAI) chatJSON(ctx context.Context, request *openai.ChatCom
 		return "", nil, err
 	}
 
-	text := resp.Choices[0].Message.Content
+	text, err := openAIResponseText(&resp)
+	if err != nil {
+		return "", nilOpenaiRespWithUsage(&resp), err
+	}
 
 	if strict {
-		resMap := make(map[string]any)
-		_ = json.Unmarshal([]byte(text), &resMap)
+		// on malformed output keep the raw text, so it fails parsing and gets retried
+		resMap := make(map[string]json.RawMessage)
+		if err := json.Unmarshal([]byte(text), &resMap); err == nil {
+			if inner, ok := resMap[structName]; ok {
+				text = string(inner)
+			}
+		}
+	}
+
+	return text, &resp, nil
+}
+
+// chatStructuredOutputs sends the root schema itself as a strict `json_schema`
+// response format, without a wrapper object or schema system prompt.
+func (i *InstructorOpenAI) chatStructuredOutputs(ctx context.Context, request *openai.ChatCompletionRequest, schema *Schema) (string, *openai.ChatCompletionResponse, error) {
+
+	responseFormat, err := createOpenAIResponseFormat(schema)
+	if err != nil {
+		return "", nil, err
+	}
+
+	request.ResponseFormat = responseFormat
+
+	resp, err := i.Client.CreateChatCompletion(ctx, *request)
+	if err != nil {
+		return "", nil, err
+	}
 
-		cleanedText, _ := json.Marshal(resMap[structName])
-		text = string(cleanedText)
+	text, err := openAIResponseText(&resp)
+	if err != nil {
+		return "", nilOpenaiRespWithUsage(&resp), err
 	}
 
 	return text, &resp, nil
@@ -175,7 +320,10 @@ func (i *InstructorOpenAI) chatJSONSchema(ctx context.Context, request *openai.C
 		return "", nil, err
 	}
 
-	text := resp.Choices[0].Message.Content
+	text, err := openAIResponseText(&resp)
+	if err != nil {
+		return "", nilOpenaiRespWithUsage(&resp), err
+	}
 
 	return text, &resp, nil
 }
@@ -206,6 +354,9 @@ func (i *InstructorOpenAI) addUsageSumToResponse(response interface{}, usage *Us
 	if !ok {
 		return response, fmt.Errorf("internal type error: expected *openai.ChatCompletionResponse, got %T", response)
 	}
+	if resp == nil {
+		return i.emptyResponseWithUsageSum(usage), nil
+	}
 
 	resp.Usage.PromptTokens += usage.InputTokens
 	resp.Usage.CompletionTokens += usage.OutputTokens
@@ -216,7 +367,7 @@ func (i *InstructorOpenAI) addUsageSumToResponse(response interface{}, usage *Us
 
 func (i *InstructorOpenAI) countUsageFromResponse(response interface{}, usage *UsageSum) *UsageSum {
 	resp, ok := response.(*openai.ChatCompletionResponse)
-	if !ok {
+	if !ok || resp == nil {
 		return usage
 	}
 
@@ -244,9 +395,17 @@ Make sure to return an instance of the JSON, not the schema itself
 	return msg
 }
 
-func createOpenAITools(schema *Schema, strict bool) []openai.Tool {
-	tools := make([]openai.Tool, 0, len(schema.Functions))
-	for _, function := range schema.Functions {
+func createOpenAITools(schema *Schema, strict bool) ([]openai.Tool, error) {
+	functions := schema.Functions
+	if strict {
+		var err error
+		if _, functions, err = schema.Strict(); err != nil {
+			return nil, err
+		}
+	}
+
+	tools := make([]openai.Tool, 0, len(functions))
+	for _, function := range functions {
 		f := openai.FunctionDefinition{
 			Name:        function.Name,
 			Description: function.Description,
@@ -259,7 +418,86 @@ func createOpenAITools(schema *Schema, strict bool) []openai.Tool {
 		}
 		tools = append(tools, t)
 	}
-	return tools
+	return tools, nil
+}
+
+// createOpenAIResponseFormat uses the strict parameters of the root tool as the
+// schema, i.e. the root object inlined with its nested types under $defs.
+// Unions, exposed as one tool per variant, use their envelope instead.
+func createOpenAIResponseFormat(schema *Schema) (*openai.ChatCompletionResponseFormat, error) {
+	strictSchema, functions, err := schema.Strict()
+	if err != nil {
+		return nil, err
+	}
+	if schema.union != nil {
+		functions = []FunctionDefinition{{
+			Name:       schema.NameFromRef(),
+			Parameters: strictSchema,
+		}}
+	}
+	if len(functions) != 1 {
+		return nil, fmt.Errorf("mode '%s' requires a single root response type, got %d definitions", ModeStructuredOutputs, len(functions))
+	}
+
+	schemaJSON, err := json.Marshal(functions[0].Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+			Name:        functions[0].Name,
+			Description: functions[0].Description,
+			Schema:      json.RawMessage(schemaJSON),
+			Strict:      true,
+		},
+	}, nil
+}
+
+// openAIResponseText returns the content of the first choice, or a typed error
+// if the model refused, ran out of tokens or was filtered.
+func openAIResponseText(resp *openai.ChatCompletionResponse) (string, error) {
+	if err := checkOpenAIResponse(resp); err != nil {
+		return "", err
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+func checkOpenAIResponse(resp *openai.ChatCompletionResponse) error {
+	if len(resp.Choices) == 0 {
+		return errors.New("received no choices from model")
+	}
+
+	choice := resp.Choices[0]
+
+	if choice.Message.Refusal != "" {
+		return &RefusalError{Provider: ProviderOpenAI, Refusal: choice.Message.Refusal}
+	}
+
+	switch choice.FinishReason {
+	case openai.FinishReasonLength:
+		return &TruncationError{Provider: ProviderOpenAI, Reason: string(choice.FinishReason)}
+	case openai.FinishReasonContentFilter:
+		return &ContentFilterError{Provider: ProviderOpenAI, Reason: string(choice.FinishReason)}
+	}
+
+	return nil
+}
+
+// createOpenAIToolChoice forces the model to call the response tool, or any
+// tool when the response type has no root definition (e.g. slices) or the
+// tools are called in parallel.
+func createOpenAIToolChoice(schema *Schema) any {
+	if _, root := rootDefinition(schema.Schema); root == nil || len(schema.Functions) != 1 || schema.parallel != nil {
+		return "required"
+	}
+	return openai.ToolChoice{
+		Type: openai.ToolTypeFunction,
+		Function: openai.ToolFunction{
+			Name: schema.Functions[0].Name,
+		},
+	}
 }
 
 func nilOpenaiRespWithUsage(resp *openai.ChatCompletionResponse) *openai.ChatCompletionResponse {
@@ -34,22 +34,43 @@ func (i *InstructorOpenAI) chatStream(ctx context.Context, request interface{},
 		return nil, errors.New("streaming is not enabled in request type; use CreateChatCompletion for synchronous completion")
 	}
 
-	switch i.Mode() {
+	// have OpenAI report the usage of the stream for the rate limiter;
+	// Azure OpenAI and OpenAI-compatible servers may not support it
+	if i.rateLimiter != nil && i.endpoint == "" && req.StreamOptions == nil {
+		req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+
+	mode := i.requestMode(request, true)
+
+	ctx, err := i.withExtraBody(ctx, mode, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
 	case ModeToolCall:
 		return i.chatToolCallStream(ctx, &req, schema, false)
 	case ModeToolCallStrict:
 		return i.chatToolCallStream(ctx, &req, schema, true)
 	case ModeJSON:
 		return i.chatJSONStream(ctx, &req, schema)
-	case ModeJSONSchema:
+	case ModeJSONSchema, ModeGrammar:
 		return i.chatJSONSchemaStream(ctx, &req, schema)
+	case ModeStructuredOutputs:
+		return i.chatStructuredOutputsStream(ctx, &req, schema)
 	default:
-		return nil, fmt.Errorf("mode '%s' is not supported for %s", i.Mode(), i.Provider())
+		return nil, fmt.Errorf("mode '%s' is not supported for %s", mode, i.Provider())
 	}
 }
 
 func (i *InstructorOpenAI) chatToolCallStream(ctx context.Context, request *openai.ChatCompletionRequest, schema *Schema, strict bool) (<-chan string, error) {
-	request.Tools = createOpenAITools(schema, strict)
+	tools, err := createOpenAITools(schema, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Tools = tools
+	request.ToolChoice = createOpenAIToolChoice(schema)
 	return i.createStream(ctx, request)
 }
 
@@ -65,6 +86,16 @@ func (i *InstructorOpenAI) chatJSONSchemaStream(ctx context.Context, request *op
 	return i.createStream(ctx, request)
 }
 
+func (i *InstructorOpenAI) chatStructuredOutputsStream(ctx context.Context, request *openai.ChatCompletionRequest, schema *Schema) (<-chan string, error) {
+	responseFormat, err := createOpenAIResponseFormat(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	request.ResponseFormat = responseFormat
+	return i.createStream(ctx, request)
+}
+
 func createJSONMessageStream(schema *Schema) *openai.ChatCompletionMessage {
 	message := fmt.Sprintf(`
 Please respond with a JSON array where the elements following JSON schema:
@@ -101,6 +132,13 @@ func (i *InstructorOpenAI) createStream(ctx context.Context, request *openai.Cha
 			if err != nil {
 				return
 			}
+			if response.Usage != nil {
+				recordStreamUsage(ctx, i.countUsageFromResponse(&openai.ChatCompletionResponse{Usage: *response.Usage}, &UsageSum{}))
+			}
+			// the chunk with the usage has no choices
+			if len(response.Choices) == 0 {
+				continue
+			}
 			text := response.Choices[0].Delta.Content
 			ch <- text
 		}
@@ -0,0 +1,267 @@
+package instructor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Capabilities are the OpenAI features an OpenAI-compatible server supports.
+type Capabilities struct {
+	// ToolCalls is support for `tools` and a forced `tool_choice`.
+	ToolCalls bool
+	// StrictToolCalls is support for `strict` function definitions.
+	StrictToolCalls bool
+	// JSONObject is support for the `json_object` response format.
+	JSONObject bool
+	// JSONSchema is support for the strict `json_schema` response format.
+	JSONSchema bool
+	// Grammar is support for GBNF grammars, sent by the profile's ExtraBody
+	// in ModeGrammar.
+	Grammar bool
+}
+
+// Profile describes how an OpenAI-compatible server differs from OpenAI, for
+// use with FromOpenAICompatible.
+type Profile struct {
+	// Name of the server, used in errors.
+	Name string
+
+	Capabilities Capabilities
+
+	// ExtraBody returns the fields to add to the body of a chat completion
+	// request in the given mode, e.g. for constrained decoding. Fields
+	// returned replace those of the request. Optional.
+	ExtraBody func(mode Mode, schema *Schema) (map[string]any, error)
+
+	// UnsupportedFields are removed from the body of requests, as the server
+	// rejects them.
+	UnsupportedFields []string
+}
+
+// ProfileVLLM is vLLM, which constrains JSON output to the schema with
+// `guided_json`.
+var ProfileVLLM = Profile{
+	Name: "vLLM",
+	Capabilities: Capabilities{
+		ToolCalls:  true,
+		JSONObject: true,
+		JSONSchema: true,
+	},
+	ExtraBody: func(mode Mode, schema *Schema) (map[string]any, error) {
+		switch mode {
+		case ModeJSON, ModeJSONSchema:
+			return map[string]any{"guided_json": json.RawMessage(schema.String)}, nil
+		}
+		return nil, nil
+	},
+}
+
+// ProfileLlamaCpp is the llama.cpp server, which constrains JSON output to the
+// schema with `json_schema`, or to its grammar with `grammar` in ModeGrammar.
+var ProfileLlamaCpp = Profile{
+	Name: "llama.cpp",
+	Capabilities: Capabilities{
+		ToolCalls:  true,
+		JSONObject: true,
+		JSONSchema: true,
+		Grammar:    true,
+	},
+	ExtraBody: func(mode Mode, schema *Schema) (map[string]any, error) {
+		switch mode {
+		case ModeJSON, ModeJSONSchema:
+			return map[string]any{"json_schema": json.RawMessage(schema.String)}, nil
+		case ModeGrammar:
+			grammar, err := schema.Grammar()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"grammar": grammar}, nil
+		}
+		return nil, nil
+	},
+}
+
+// ProfileGroq is Groq, which has no `json_schema` response format or strict
+// tools and rejects log probabilities and logit biases.
+var ProfileGroq = Profile{
+	Name: "Groq",
+	Capabilities: Capabilities{
+		ToolCalls:  true,
+		JSONObject: true,
+	},
+	UnsupportedFields: []string{"logprobs", "top_logprobs", "logit_bias"},
+}
+
+// ProfileTogether is Together AI, which constrains JSON mode output to a
+// schema given along with the `json_object` response format.
+var ProfileTogether = Profile{
+	Name: "Together AI",
+	Capabilities: Capabilities{
+		ToolCalls:  true,
+		JSONObject: true,
+	},
+	ExtraBody: func(mode Mode, schema *Schema) (map[string]any, error) {
+		if mode != ModeJSON {
+			return nil, nil
+		}
+		return map[string]any{
+			"response_format": map[string]any{
+				"type":   openai.ChatCompletionResponseFormatTypeJSONObject,
+				"schema": json.RawMessage(schema.String),
+			},
+		}, nil
+	},
+}
+
+// SupportsMode reports whether the server supports mode.
+func (p Profile) SupportsMode(mode Mode) bool {
+	return p.checkMode(mode) == nil
+}
+
+func (p Profile) checkMode(mode Mode) error {
+	var missing string
+
+	switch mode {
+	case ModeToolCall:
+		if !p.Capabilities.ToolCalls {
+			missing = "tool calls"
+		}
+	case ModeToolCallStrict:
+		if !p.Capabilities.ToolCalls || !p.Capabilities.StrictToolCalls {
+			missing = "strict tool calls"
+		}
+	case ModeJSON:
+		if !p.Capabilities.JSONObject {
+			missing = "json_object response format"
+		}
+	case ModeJSONStrict, ModeStructuredOutputs:
+		if !p.Capabilities.JSONSchema {
+			missing = "json_schema response format"
+		}
+	case ModeGrammar:
+		if !p.Capabilities.Grammar {
+			missing = "grammars"
+		}
+	}
+
+	if missing != "" {
+		return fmt.Errorf("mode '%s' is not supported by %s: no %s", mode, p.Name, missing)
+	}
+	return nil
+}
+
+// FromOpenAICompatible returns an OpenAI client for a server speaking the
+// OpenAI API, adjusted to its differences by the profile. A mode the server
+// doesn't support is reported by Err.
+//
+//	config := openai.DefaultConfig("")
+//	config.BaseURL = "http://localhost:8000/v1"
+//	client := instructor.FromOpenAICompatible(config, instructor.ProfileVLLM, instructor.WithMode(instructor.ModeJSON))
+func FromOpenAICompatible(config openai.ClientConfig, profile Profile, opts ...Options) *InstructorOpenAI {
+	doer := config.HTTPClient
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	config.HTTPClient = &profileDoer{doer: doer, profile: &profile}
+
+	opts = append(opts, Options{profile: &profile, endpoint: toPtr(config.BaseURL)})
+
+	return FromOpenAI(openai.NewClientWithConfig(config), opts...)
+}
+
+// ModeCapabilities returns the modes supported by the client, those of OpenAI
+// narrowed down to its Azure api-version or the profile of its server.
+func (i *InstructorOpenAI) ModeCapabilities() ModeCapabilities {
+	capabilities := ProviderModes(i.provider)
+	if i.profile != nil && i.profile.Capabilities.Grammar {
+		capabilities.Modes = append(slices.Clone(capabilities.Modes), ModeGrammar)
+		capabilities.StreamModes = append(slices.Clone(capabilities.StreamModes), ModeGrammar)
+	}
+
+	return capabilities.filter(func(mode Mode) bool {
+		return i.checkMode(mode) == nil
+	})
+}
+
+// checkMode returns an error if the server of the client doesn't support
+// mode.
+func (i *InstructorOpenAI) checkMode(mode Mode) error {
+	if err := i.checkAzureMode(mode); err != nil {
+		return err
+	}
+	if i.profile != nil {
+		return i.profile.checkMode(mode)
+	}
+	if mode == ModeGrammar {
+		return fmt.Errorf("mode '%s' is not supported by %s, use FromOpenAICompatible with a server supporting grammars", mode, i.Provider())
+	}
+	return nil
+}
+
+type extraBodyKey struct{}
+
+// withExtraBody returns ctx carrying the extra body fields of the profile in
+// mode for the requests sent with it.
+func (i *InstructorOpenAI) withExtraBody(ctx context.Context, mode Mode, schema *Schema) (context.Context, error) {
+	if i.profile == nil || i.profile.ExtraBody == nil {
+		return ctx, nil
+	}
+
+	fields, err := i.profile.ExtraBody(mode, schema)
+	if err != nil || len(fields) == 0 {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, extraBodyKey{}, fields), nil
+}
+
+// profileDoer rewrites the bodies of requests for the server of a profile.
+type profileDoer struct {
+	doer    openai.HTTPDoer
+	profile *Profile
+}
+
+func (d *profileDoer) Do(req *http.Request) (*http.Response, error) {
+	fields, _ := req.Context().Value(extraBodyKey{}).(map[string]any)
+
+	if req.Body == nil || (len(fields) == 0 && len(d.profile.UnsupportedFields) == 0) {
+		return d.doer.Do(req)
+	}
+
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(b, &body); err != nil {
+		return nil, fmt.Errorf("decoding request: %w", err)
+	}
+
+	for _, field := range d.profile.UnsupportedFields {
+		delete(body, field)
+	}
+	for field, value := range fields {
+		body[field] = value
+	}
+
+	if b, err = json.Marshal(body); err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.ContentLength = int64(len(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+
+	return d.doer.Do(req)
+}
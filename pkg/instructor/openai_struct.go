@@ -1,6 +1,9 @@
 package instructor
 
 import (
+	"fmt"
+	"reflect"
+
 	openai "github.com/sashabaranov/go-openai"
 )
 
@@ -9,8 +12,26 @@ type InstructorOpenAI struct {
 
 	provider   Provider
 	mode       Mode
+	streamMode Mode
+	// auto is set if the modes were picked by ModeAuto
+	auto       bool
 	maxRetries int
 	validate   bool
+
+	rateLimiter       *RateLimiter
+	cache             *ResponseCache
+	truncationRetries int
+
+	// err is why the client can't be used, e.g. an unsupported mode
+	err error
+
+	// api-version of an Azure OpenAI client, empty for OpenAI
+	azureAPIVersion string
+	// profile of an OpenAI-compatible server, nil for OpenAI
+	profile *Profile
+	// endpoint of an Azure OpenAI resource or OpenAI-compatible server, empty
+	// for OpenAI
+	endpoint string
 }
 
 var _ Instructor = &InstructorOpenAI{}
@@ -23,10 +44,29 @@ func FromOpenAI(client *openai.Client, opts ...Options) *InstructorOpenAI {
 		Client: client,
 
 		provider:   ProviderOpenAI,
-		mode:       *options.Mode,
 		maxRetries: *options.MaxRetries,
 		validate:   *options.validate,
+
+		rateLimiter:       options.rateLimiter,
+		cache:             options.cache,
+		truncationRetries: *options.truncationRetries,
+	}
+	if options.azureAPIVersion != nil {
+		i.azureAPIVersion = *options.azureAPIVersion
+	}
+	i.profile = options.profile
+	if options.endpoint != nil {
+		i.endpoint = *options.endpoint
 	}
+
+	if options.Mode != nil {
+		if err := i.checkMode(*options.Mode); err != nil {
+			i.mode, i.streamMode, i.err = *options.Mode, *options.Mode, fmt.Errorf("instructor: %w", err)
+			return i
+		}
+	}
+	i.mode, i.streamMode, i.auto, i.err = resolveModes(i.provider, i.ModeCapabilities(), options.Mode)
+
 	return i
 }
 
@@ -36,9 +76,60 @@ func (i *InstructorOpenAI) Provider() Provider {
 func (i *InstructorOpenAI) Mode() Mode {
 	return i.mode
 }
+func (i *InstructorOpenAI) StreamMode() Mode {
+	return i.streamMode
+}
+
+// requestMode returns the mode of a synchronous or streaming request, which
+// with ModeAuto is the best mode the model of the request supports.
+func (i *InstructorOpenAI) requestMode(request interface{}, stream bool) Mode {
+	return modelMode(i, i.auto, i.ModeCapabilities(), request, stream)
+}
 func (i *InstructorOpenAI) MaxRetries() int {
 	return i.maxRetries
 }
 func (i *InstructorOpenAI) Validate() bool {
 	return i.validate
 }
+func (i *InstructorOpenAI) RateLimiter() *RateLimiter {
+	return i.rateLimiter
+}
+func (i *InstructorOpenAI) Cache() *ResponseCache {
+	return i.cache
+}
+func (i *InstructorOpenAI) TruncationRetries() int {
+	return i.truncationRetries
+}
+func (i *InstructorOpenAI) Err() error {
+	return i.err
+}
+
+// cacheScope keeps the cached responses of Azure OpenAI resources,
+// OpenAI-compatible servers and clients with another base URL apart from those
+// of OpenAI and each other.
+func (i *InstructorOpenAI) cacheScope() string {
+	if i.endpoint != "" {
+		return i.endpoint
+	}
+	if baseURL := clientBaseURL(i.Client); baseURL != openai.DefaultConfig("").BaseURL {
+		return baseURL
+	}
+	return ""
+}
+
+// clientBaseURL returns the base URL client was configured with, or "" if it
+// can't be told. go-openai keeps the config of a client unexported.
+func clientBaseURL(client *openai.Client) string {
+	if client == nil {
+		return ""
+	}
+	config := reflect.ValueOf(client).Elem().FieldByName("config")
+	if !config.IsValid() || config.Kind() != reflect.Struct {
+		return ""
+	}
+	baseURL := config.FieldByName("BaseURL")
+	if !baseURL.IsValid() || baseURL.Kind() != reflect.String {
+		return ""
+	}
+	return baseURL.String()
+}
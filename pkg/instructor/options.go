@@ -9,13 +9,27 @@ type Options struct {
 	Mode       *Mode
 	MaxRetries *int
 	validate   *bool
+
+	rateLimiter       *RateLimiter
+	cache             *ResponseCache
+	truncationRetries *int
 	// Provider specific options:
+
+	// api-version of an Azure OpenAI client
+	azureAPIVersion *string
+	// profile of an OpenAI-compatible server
+	profile *Profile
+	// endpoint of an Azure OpenAI resource or OpenAI-compatible server
+	endpoint *string
 }
 
+// defaultOptions leave the mode unset, for clients to pick ModeDefault or
+// ModeAuto depending on what they support.
 var defaultOptions = Options{
-	Mode:       toPtr(ModeDefault),
 	MaxRetries: toPtr(DefaultMaxRetries),
 	validate:   toPtr(DefaultValidator),
+
+	truncationRetries: toPtr(0),
 }
 
 func WithMode(mode Mode) Options {
@@ -30,6 +44,31 @@ func WithValidation() Options {
 	return Options{validate: toPtr(true)}
 }
 
+// WithRateLimit throttles the client to the given requests and tokens per
+// minute. Use 0 to leave a dimension unlimited.
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Options {
+	return Options{rateLimiter: NewRateLimiter(requestsPerMinute, tokensPerMinute)}
+}
+
+// WithRateLimiter shares an existing RateLimiter, e.g. across several clients
+// using the same API key.
+func WithRateLimiter(limiter *RateLimiter) Options {
+	return Options{rateLimiter: limiter}
+}
+
+// WithCache serves repeated synchronous extractions from cache.
+func WithCache(cache *ResponseCache) Options {
+	return Options{cache: cache}
+}
+
+// WithTruncationRetries retries a response cut off at the token limit up to
+// n times, doubling the max tokens of the request each time. Truncation
+// retries don't count towards MaxRetries. Without it a *TruncationError is
+// returned.
+func WithTruncationRetries(n int) Options {
+	return Options{truncationRetries: toPtr(n)}
+}
+
 func mergeOption(old, new Options) Options {
 	if new.Mode != nil {
 		old.Mode = new.Mode
@@ -40,6 +79,24 @@ func mergeOption(old, new Options) Options {
 	if new.validate != nil {
 		old.validate = new.validate
 	}
+	if new.rateLimiter != nil {
+		old.rateLimiter = new.rateLimiter
+	}
+	if new.cache != nil {
+		old.cache = new.cache
+	}
+	if new.truncationRetries != nil {
+		old.truncationRetries = new.truncationRetries
+	}
+	if new.azureAPIVersion != nil {
+		old.azureAPIVersion = new.azureAPIVersion
+	}
+	if new.profile != nil {
+		old.profile = new.profile
+	}
+	if new.endpoint != nil {
+		old.endpoint = new.endpoint
+	}
 
 	return old
 }
@@ -0,0 +1,196 @@
+package instructor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/invopop/jsonschema"
+)
+
+// parallelCall is how joinToolCalls reports the calls of a parallel schema, so
+// every call can be decoded into the type of its tool.
+type parallelCall struct {
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// newParallelSchema combines the tools of several response types into one
+// schema. Every type must expose a single tool.
+func newParallelSchema(responseTypes []any) (*Schema, error) {
+	if len(responseTypes) == 0 {
+		return nil, errors.New("at least one response type is required")
+	}
+
+	s := &Schema{
+		Schema: &jsonschema.Schema{
+			Type:        "object",
+			Definitions: jsonschema.Definitions{},
+		},
+		parallel: make(map[string]reflect.Type, len(responseTypes)),
+	}
+
+	names := make([]string, 0, len(responseTypes))
+
+	for _, responseType := range responseTypes {
+		t := reflect.TypeOf(responseType)
+		if t == nil {
+			return nil, errors.New("response types must not be nil")
+		}
+
+		ts, err := schemas.schema(t)
+		if err != nil {
+			return nil, err
+		}
+		if len(ts.Functions) != 1 {
+			return nil, fmt.Errorf("response type %s must expose a single tool, got %d", t, len(ts.Functions))
+		}
+
+		function := ts.Functions[0]
+		if _, ok := s.parallel[function.Name]; ok {
+			return nil, fmt.Errorf("more than one response type is named %q", function.Name)
+		}
+
+		s.parallel[function.Name] = derefType(t)
+		s.Functions = append(s.Functions, function)
+		names = append(names, function.Name)
+
+		for name, def := range ts.Definitions {
+			s.Schema.Definitions[name] = def
+		}
+	}
+
+	b, err := json.MarshalIndent(s.Functions, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	s.String = string(b)
+	s.name = strings.Join(names, "Or")
+
+	return s, nil
+}
+
+// CreateParallel lets the model call any number of tools in one response, one
+// tool per response type, e.g. to plan a mix of actions:
+//
+//	actions, _, err := instructor.CreateParallel(ctx, client, request, Search{}, Lookup{}, Calculate{})
+//	for _, action := range actions {
+//		switch action := action.(type) {
+//		case *Search:
+//		case *Lookup:
+//		...
+//
+// request is the provider request as passed to the client. The results are
+// pointers to new values of the called types, in the order the model called
+// them, and the response is the matching provider response. Requires
+// ModeToolCall or ModeToolCallStrict with OpenAI, Anthropic, Cohere, Mistral
+// or Bedrock.
+func CreateParallel(ctx context.Context, i Instructor, request interface{}, responseTypes ...any) ([]any, interface{}, error) {
+	if err := i.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	switch mode := i.requestMode(request, false); mode {
+	case ModeToolCall, ModeToolCallStrict:
+	default:
+		return nil, nil, fmt.Errorf("parallel tool calls require mode %s or %s, got %s", ModeToolCall, ModeToolCallStrict, mode)
+	}
+	if i.Provider() == ProviderGoogleAI {
+		return nil, nil, fmt.Errorf("parallel tool calls are not supported for %s", i.Provider())
+	}
+
+	schema, err := newParallelSchema(responseTypes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if i.Validate() {
+		validate = validator.New()
+	}
+
+	// keep a running total of usage
+	usage := &UsageSum{}
+
+	// why the last tool calls were rejected, returned once out of retries
+	var lastErr error
+
+	for attempt := 0; attempt <= i.MaxRetries(); attempt++ {
+
+		reservation, err := i.RateLimiter().wait(ctx, estimateTokens(request, schema))
+		if err != nil {
+			return nil, i.emptyResponseWithUsageSum(usage), err
+		}
+
+		text, resp, err := i.chat(ctx, request, schema)
+		if err != nil {
+			return nil, i.emptyResponseWithResponseUsage(resp), err
+		}
+
+		i.RateLimiter().record(reservation, i.countUsageFromResponse(resp, &UsageSum{}))
+
+		results, err := schema.decodeParallel(ctx, i, text)
+		if err == nil {
+			response, err := i.addUsageSumToResponse(resp, usage)
+			return results, response, err
+		}
+
+		i.countUsageFromResponse(resp, usage)
+		lastErr = err
+
+		request = i.reask(request, text, fmt.Sprintf("Your tool calls were invalid: %s. Call the tools again with corrected arguments.", err))
+	}
+
+	return nil, i.emptyResponseWithUsageSum(usage), retriesExhausted(lastErr)
+}
+
+func (s *Schema) decodeParallel(ctx context.Context, i Instructor, text string) ([]any, error) {
+	var calls []parallelCall
+	if err := json.Unmarshal([]byte(text), &calls); err != nil {
+		return nil, err
+	}
+
+	results := make([]any, 0, len(calls))
+
+	for idx, call := range calls {
+		t, ok := s.parallel[call.Name]
+		if !ok {
+			return nil, fmt.Errorf("call %d: unknown tool %q", idx+1, call.Name)
+		}
+
+		result := reflect.New(t).Interface()
+		if err := decodeArguments(ctx, i, call.Arguments, result); err != nil {
+			return nil, fmt.Errorf("call %d to %s: %w", idx+1, call.Name, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// decodeArguments decodes the arguments of a tool call into v and checks them
+// like an item, unwrapping the envelope the tool of a non-struct type takes.
+func decodeArguments(ctx context.Context, i Instructor, arguments json.RawMessage, v any) error {
+	envelope := schemas.envelope(reflect.TypeOf(v))
+
+	target, err := unmarshalResponse(string(arguments), v, envelope)
+	if err != nil {
+		return err
+	}
+
+	if err := checkItem(ctx, i, target); err != nil {
+		return err
+	}
+
+	if envelope != nil {
+		unwrapEnvelope(target, v)
+	}
+
+	return nil
+}
@@ -7,4 +7,7 @@ const (
 	ProviderAnthropic Provider = "Anthropic"
 	ProviderCohere    Provider = "Cohere"
 	ProviderGoogleAI  Provider = "GoogleAI"
+	ProviderOllama    Provider = "Ollama"
+	ProviderMistral   Provider = "Mistral"
+	ProviderBedrock   Provider = "Bedrock"
 )
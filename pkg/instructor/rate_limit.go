@@ -0,0 +1,189 @@
+package instructor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a request cannot be admitted by the
+// client-side rate limiter before the context deadline expires.
+var ErrRateLimited = errors.New("client-side rate limit exceeded")
+
+const rateLimitWindow = time.Minute
+
+// RateLimiter enforces requests-per-minute and tokens-per-minute limits over a
+// sliding one minute window. A limit of 0 disables that dimension.
+//
+// Token usage is estimated from the size of the request and schema before the
+// call, then corrected with the usage reported by the provider once the
+// response arrives, or once a stream reports it at its end. A single limiter
+// may be shared by several clients. Create one with NewRateLimiter; its limits
+// are fixed once created.
+type RateLimiter struct {
+	requestsPerMinute int
+	tokensPerMinute   int
+
+	mu      sync.Mutex
+	entries []*rateLimitEntry
+}
+
+type rateLimitEntry struct {
+	at     time.Time
+	tokens int
+}
+
+// NewRateLimiter returns a RateLimiter allowing the given requests and tokens
+// per minute. Use 0 to leave a dimension unlimited.
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	return &RateLimiter{
+		requestsPerMinute: requestsPerMinute,
+		tokensPerMinute:   tokensPerMinute,
+	}
+}
+
+// Wait blocks until a request with the given estimated token count fits within
+// the limits. If the context has a deadline that would pass before capacity
+// frees up, it fails fast with ErrRateLimited instead of waiting.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	_, err := l.wait(ctx, tokens)
+	return err
+}
+
+func (l *RateLimiter) wait(ctx context.Context, tokens int) (*rateLimitEntry, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	if l.tokensPerMinute > 0 && tokens > l.tokensPerMinute {
+		// never admissible otherwise, let it through once the window is empty
+		tokens = l.tokensPerMinute
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.prune(now)
+		delay := l.delay(now, tokens)
+		if delay <= 0 {
+			entry := &rateLimitEntry{at: now, tokens: tokens}
+			l.entries = append(l.entries, entry)
+			l.mu.Unlock()
+			return entry, nil
+		}
+		l.mu.Unlock()
+
+		if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+			return nil, fmt.Errorf("%w: next slot in %s is past the context deadline", ErrRateLimited, delay.Round(time.Millisecond))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// record replaces the estimated token count of an admitted request with the
+// actual usage reported by the provider.
+func (l *RateLimiter) record(entry *rateLimitEntry, usage *UsageSum) {
+	if l == nil || entry == nil || usage == nil {
+		return
+	}
+
+	tokens := usage.TotalTokens
+	if tokens == 0 {
+		tokens = usage.InputTokens + usage.OutputTokens
+	}
+	if tokens == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	entry.tokens = tokens
+	l.mu.Unlock()
+}
+
+type streamUsageKey struct{}
+
+// withStreamUsage returns ctx for a stream admitted with entry, so that the
+// usage the provider reports at the end of the stream replaces the estimate.
+func (l *RateLimiter) withStreamUsage(ctx context.Context, entry *rateLimitEntry) context.Context {
+	if l == nil || entry == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, streamUsageKey{}, func(usage *UsageSum) {
+		l.record(entry, usage)
+	})
+}
+
+// recordStreamUsage records the usage reported by the provider of a stream
+// with the rate limiter that admitted it, if any.
+func recordStreamUsage(ctx context.Context, usage *UsageSum) {
+	if record, ok := ctx.Value(streamUsageKey{}).(func(*UsageSum)); ok {
+		record(usage)
+	}
+}
+
+func (l *RateLimiter) prune(now time.Time) {
+	cutoff := now.Add(-rateLimitWindow)
+
+	idx := 0
+	for idx < len(l.entries) && !l.entries[idx].at.After(cutoff) {
+		idx++
+	}
+	l.entries = l.entries[idx:]
+}
+
+func (l *RateLimiter) delay(now time.Time, tokens int) time.Duration {
+	var delay time.Duration
+
+	if l.requestsPerMinute > 0 && len(l.entries) >= l.requestsPerMinute {
+		oldest := l.entries[len(l.entries)-l.requestsPerMinute]
+		delay = oldest.at.Add(rateLimitWindow).Sub(now)
+	}
+
+	if l.tokensPerMinute > 0 {
+		used := 0
+		for _, e := range l.entries {
+			used += e.tokens
+		}
+
+		for _, e := range l.entries {
+			if used+tokens <= l.tokensPerMinute {
+				break
+			}
+			used -= e.tokens
+			delay = max(delay, e.at.Add(rateLimitWindow).Sub(now))
+		}
+	}
+
+	return delay
+}
+
+// estimateTokens roughly approximates the prompt size in tokens using the
+// common ~4 characters per token heuristic.
+func estimateTokens(request interface{}, schema interface{}) int {
+	size := 0
+
+	if b, err := json.Marshal(request); err == nil {
+		size += len(b)
+	}
+
+	switch s := schema.(type) {
+	case *Schema:
+		size += len(s.String)
+	default:
+		if b, err := json.Marshal(s); err == nil {
+			size += len(b)
+		}
+	}
+
+	return size/4 + 1
+}
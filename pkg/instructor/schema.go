@@ -3,7 +3,10 @@ package instructor
 import (
 	"encoding/json"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/invopop/jsonschema"
 )
@@ -13,6 +16,21 @@ type Schema struct {
 	String string
 
 	Functions []FunctionDefinition
+
+	name  string
+	union *union
+
+	// parallel maps tool names to response types for CreateParallel
+	parallel map[string]reflect.Type
+
+	strictOnce      sync.Once
+	strictSchema    *jsonschema.Schema
+	strictFunctions []FunctionDefinition
+	strictErr       error
+
+	grammarOnce sync.Once
+	grammar     string
+	grammarErr  error
 }
 
 type Function struct {
@@ -28,7 +46,12 @@ type FunctionDefinition struct {
 
 func NewSchema(t reflect.Type) (*Schema, error) {
 
-	schema := jsonschema.ReflectFromType(t)
+	reflected := t
+	if envelope := schemas.envelope(t); envelope != nil {
+		reflected = envelope
+	}
+
+	schema := reflectSchema(reflected)
 
 	str, err := json.MarshalIndent(schema, "", "  ")
 	if err != nil {
@@ -42,25 +65,98 @@ func NewSchema(t reflect.Type) (*Schema, error) {
 		String: string(str),
 
 		Functions: funcs,
+
+		name:  envelopeName(t),
+		union: unionOf(derefType(t)),
+	}
+
+	if name, _ := rootDefinition(schema); name != "" {
+		s.name = name
 	}
 
 	return s, nil
 }
 
+// ToolNamer can be implemented by a response type to choose the name of the
+// tool it is exposed as in tool call modes. Defaults to the type name.
+type ToolNamer interface {
+	InstructorName() string
+}
+
+// ToolDescriber can be implemented by a response type to set the description
+// of its tool. Defaults to the `jsonschema` description of the type.
+type ToolDescriber interface {
+	InstructorDescription() string
+}
+
+// ToFunctionSchema returns the tool definitions for a response type. The root
+// type becomes the single tool, with nested types kept under $defs. Unions
+// expose one tool per variant, and types without a root definition (e.g.
+// slices) one tool per definition, sorted by name.
 func ToFunctionSchema(tType reflect.Type, tSchema *jsonschema.Schema) []FunctionDefinition {
 
-	fds := []FunctionDefinition{}
+	if u := unionOf(derefType(tType)); u != nil {
+		return unionFunctions(u, tSchema)
+	}
+
+	rootName, root := rootDefinition(tSchema)
+	if root == nil && tSchema.Type == "object" && tSchema.Properties != nil {
+		// inline root, e.g. the envelope of a non-struct response type
+		rootName, root = envelopeName(tType), tSchema
+	}
+	if root == nil {
+		return definitionFunctions(tSchema)
+	}
+
+	parameters := &jsonschema.Schema{
+		Type:                 "object",
+		Properties:           root.Properties,
+		Required:             root.Required,
+		AdditionalProperties: root.AdditionalProperties,
+		Definitions:          nestedDefinitions(rootName, tSchema.Definitions),
+	}
+
+	name := rootName
+	description := root.Description
 
-	for name, def := range tSchema.Definitions {
+	if v, ok := newValue(tType).(ToolNamer); ok {
+		name = v.InstructorName()
+	}
+	if v, ok := newValue(tType).(ToolDescriber); ok {
+		description = v.InstructorDescription()
+	}
+
+	fd := FunctionDefinition{
+		Name:        sanitizeToolName(name),
+		Description: description,
+		Parameters:  parameters,
+	}
+
+	return []FunctionDefinition{fd}
+}
+
+func definitionFunctions(tSchema *jsonschema.Schema) []FunctionDefinition {
+
+	names := make([]string, 0, len(tSchema.Definitions))
+	for name := range tSchema.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fds := make([]FunctionDefinition, 0, len(names))
+
+	for _, name := range names {
+		def := tSchema.Definitions[name]
 
 		parameters := &jsonschema.Schema{
-			Type:       "object",
-			Properties: def.Properties,
-			Required:   def.Required,
+			Type:        "object",
+			Properties:  def.Properties,
+			Required:    def.Required,
+			Definitions: nestedDefinitions(name, tSchema.Definitions),
 		}
 
 		fd := FunctionDefinition{
-			Name:        name,
+			Name:        sanitizeToolName(name),
 			Description: def.Description,
 			Parameters:  parameters,
 		}
@@ -71,6 +167,143 @@ func ToFunctionSchema(tType reflect.Type, tSchema *jsonschema.Schema) []Function
 	return fds
 }
 
+func rootDefinition(tSchema *jsonschema.Schema) (string, *jsonschema.Schema) {
+	if !strings.HasPrefix(tSchema.Ref, "#/$defs/") {
+		return "", nil
+	}
+
+	name := strings.TrimPrefix(tSchema.Ref, "#/$defs/")
+	def, ok := tSchema.Definitions[name]
+	if !ok {
+		return "", nil
+	}
+
+	return name, def
+}
+
+// nestedDefinitions returns the definitions a tool's parameters may reference,
+// dropping the tool's own definition unless it is recursive.
+func nestedDefinitions(name string, defs jsonschema.Definitions) jsonschema.Definitions {
+	if len(defs) == 0 {
+		return nil
+	}
+
+	if _, ok := defs[name]; !ok {
+		return defs
+	}
+
+	b, _ := json.Marshal(defs)
+	if strings.Contains(string(b), `"#/$defs/`+name+`"`) {
+		return defs
+	}
+
+	if len(defs) == 1 {
+		return nil
+	}
+
+	nested := make(jsonschema.Definitions, len(defs)-1)
+	for k, v := range defs {
+		if k != name {
+			nested[k] = v
+		}
+	}
+	return nested
+}
+
+func newValue(t reflect.Type) any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return reflect.New(t).Interface()
+}
+
+var (
+	typeArgPackage = regexp.MustCompile(`[\w./-]*\.`)
+	typeArgWord    = regexp.MustCompile(`\w+`)
+)
+
+// typeName names t like reflect does, but spells the type arguments of
+// generic types without package paths or brackets, e.g. `MaybePerson` for
+// `Maybe[example.com/pkg.Person]`, so the name is a valid definition and tool
+// name.
+func typeName(t reflect.Type) string {
+	name := t.Name()
+
+	open := strings.IndexByte(name, '[')
+	if open == -1 {
+		return name
+	}
+
+	args := typeArgPackage.ReplaceAllString(name[open:], "")
+	args = strings.ReplaceAll(args, "[]", "ListOf")
+
+	var b strings.Builder
+	b.WriteString(name[:open])
+	for _, word := range typeArgWord.FindAllString(args, -1) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// sanitizeToolName makes name a valid tool name for all providers: at most 64
+// letters, digits, underscores or dashes.
+func sanitizeToolName(name string) string {
+	name = strings.Trim(invalidToolNameChars.ReplaceAllString(name, "_"), "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
 func (s *Schema) NameFromRef() string {
-	return strings.Split(s.Ref, "/")[2] // ex: '#/$defs/MyStruct'
+	if name, _ := rootDefinition(s.Schema); name != "" {
+		return name // ex: '#/$defs/MyStruct'
+	}
+	return s.name
+}
+
+// root returns the object schema of the response itself, resolving the $ref to
+// its definition if needed.
+func (s *Schema) root() *jsonschema.Schema {
+	if _, def := rootDefinition(s.Schema); def != nil {
+		return def
+	}
+	return s.Schema
+}
+
+// Strict returns the schema and function definitions rewritten for OpenAI
+// strict mode, see ToStrictSchema. The conversion happens once per schema.
+func (s *Schema) Strict() (*jsonschema.Schema, []FunctionDefinition, error) {
+	s.strictOnce.Do(func() {
+		s.strictSchema, s.strictErr = ToStrictSchema(s.Schema)
+		if s.strictErr != nil {
+			return
+		}
+
+		s.strictFunctions = make([]FunctionDefinition, 0, len(s.Functions))
+		for _, function := range s.Functions {
+			parameters, err := ToStrictSchema(function.Parameters)
+			if err != nil {
+				s.strictErr = err
+				return
+			}
+
+			function.Parameters = parameters
+			s.strictFunctions = append(s.strictFunctions, function)
+		}
+	})
+
+	return s.strictSchema, s.strictFunctions, s.strictErr
+}
+
+// Grammar returns the GBNF grammar of the schema, see ToGBNF. The grammar is
+// generated once and cached.
+func (s *Schema) Grammar() (string, error) {
+	s.grammarOnce.Do(func() {
+		s.grammar, s.grammarErr = ToGBNF(s.Schema)
+	})
+
+	return s.grammar, s.grammarErr
 }
@@ -0,0 +1,168 @@
+package instructor
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// schemaRegistry caches generated schemas per response type so reflection and
+// marshalling only happen once per type. Cached schemas are shared between
+// requests and must be treated as read-only.
+type schemaRegistry struct {
+	schemas        sync.Map // reflect.Type -> *Schema
+	googleSchemas  sync.Map // reflect.Type -> *genai.Schema
+	streamWrappers sync.Map // reflect.Type -> reflect.Type
+	envelopes      sync.Map // reflect.Type -> reflect.Type
+}
+
+var schemas = &schemaRegistry{}
+
+func (r *schemaRegistry) schema(t reflect.Type) (*Schema, error) {
+	t = derefType(t)
+
+	if s, ok := r.schemas.Load(t); ok {
+		return s.(*Schema), nil
+	}
+
+	s, err := NewSchema(t)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := r.schemas.LoadOrStore(t, s)
+	return actual.(*Schema), nil
+}
+
+func (r *schemaRegistry) googleSchema(t reflect.Type) (*genai.Schema, error) {
+	t = derefType(t)
+
+	if envelope := r.envelope(t); envelope != nil {
+		t = envelope
+	}
+
+	return r.reflectGoogleSchema(t)
+}
+
+// reflectGoogleSchema returns the genai schema of t exactly, without wrapping.
+func (r *schemaRegistry) reflectGoogleSchema(t reflect.Type) (*genai.Schema, error) {
+	if s, ok := r.googleSchemas.Load(t); ok {
+		return s.(*genai.Schema), nil
+	}
+
+	s := googleReflector.Reflect(t)
+
+	actual, _ := r.googleSchemas.LoadOrStore(t, s)
+	return actual.(*genai.Schema), nil
+}
+
+// reset drops all cached schemas.
+func (r *schemaRegistry) reset() {
+	for _, m := range []*sync.Map{&r.schemas, &r.googleSchemas, &r.streamWrappers, &r.envelopes} {
+		m.Range(func(k, _ any) bool {
+			m.Delete(k)
+			return true
+		})
+	}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// envelope returns the (cached) envelope type for t, or nil if t is a struct.
+func (r *schemaRegistry) envelope(t reflect.Type) reflect.Type {
+	if e, ok := r.envelopes.Load(t); ok {
+		e, _ := e.(reflect.Type)
+		return e
+	}
+
+	e := envelopeType(t)
+	if e == nil {
+		r.envelopes.Store(t, nil)
+		return nil
+	}
+
+	actual, _ := r.envelopes.LoadOrStore(t, e)
+	return actual.(reflect.Type)
+}
+
+// streamItemType returns the type each streamed element of t is decoded into,
+// t itself or its envelope.
+func (r *schemaRegistry) streamItemType(t reflect.Type) reflect.Type {
+	if envelope := r.envelope(t); envelope != nil {
+		return envelope
+	}
+	return t
+}
+
+// streamWrapper returns the `{"items": [...]}` type used to stream elements of t.
+func (r *schemaRegistry) streamWrapper(t reflect.Type) reflect.Type {
+	if w, ok := r.streamWrappers.Load(t); ok {
+		return w.(reflect.Type)
+	}
+
+	w := reflect.StructOf([]reflect.StructField{
+		{
+			Name:      "Items",
+			Type:      reflect.SliceOf(r.streamItemType(t)),
+			Tag:       `json:"items"`,
+			Anonymous: false,
+		},
+	})
+
+	actual, _ := r.streamWrappers.LoadOrStore(t, w)
+	return actual.(reflect.Type)
+}
+
+// forProvider returns the schema flavour the given provider expects for t.
+func (r *schemaRegistry) forProvider(provider Provider, t reflect.Type) (interface{}, error) {
+	if provider == ProviderGoogleAI {
+		return r.googleSchema(t)
+	}
+	return r.schema(t)
+}
+
+// streamForProvider returns the schema used to stream a sequence of t.
+func (r *schemaRegistry) streamForProvider(provider Provider, t reflect.Type) (interface{}, error) {
+	if provider == ProviderGoogleAI {
+		return r.reflectGoogleSchema(reflect.SliceOf(r.streamItemType(t)))
+	}
+	return r.schema(r.streamWrapper(t))
+}
+
+// RegisterSchemas precomputes the schemas for the given response values (e.g.
+// `&MyStruct{}` or `MyStruct{}`) for every provider and for streaming, so the
+// first request doesn't pay for reflection. Call it at startup to warm the cache
+// and to surface schema generation errors early.
+func RegisterSchemas(responses ...any) error {
+	for _, response := range responses {
+		t := reflect.TypeOf(response)
+
+		for _, provider := range []Provider{ProviderOpenAI, ProviderGoogleAI} {
+			if _, err := schemas.forProvider(provider, t); err != nil {
+				return err
+			}
+			if _, err := schemas.streamForProvider(provider, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SchemaOf returns the (cached) JSON schema and function definitions generated
+// for a response value, as sent to OpenAI, Anthropic and Cohere.
+func SchemaOf(response any) (*Schema, error) {
+	return schemas.schema(reflect.TypeOf(response))
+}
+
+// GoogleAISchemaOf returns the (cached) genai schema generated for a response
+// value, as sent to GoogleAI.
+func GoogleAISchemaOf(response any) (*genai.Schema, error) {
+	return schemas.googleSchema(reflect.TypeOf(response))
+}
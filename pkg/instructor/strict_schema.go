@@ -0,0 +1,248 @@
+package instructor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Limits of OpenAI structured outputs in strict mode.
+const (
+	strictMaxProperties = 100
+	strictMaxDepth      = 5
+	strictMaxEnumValues = 500
+)
+
+// StrictSchemaError lists the parts of a schema that can't be expressed in
+// OpenAI strict mode. It is returned before any request is sent.
+type StrictSchemaError struct {
+	Issues []string
+}
+
+func (e *StrictSchemaError) Error() string {
+	return "schema is not compatible with OpenAI strict mode:\n  - " + strings.Join(e.Issues, "\n  - ")
+}
+
+// ToStrictSchema returns a copy of s rewritten for OpenAI strict mode:
+//
+//   - every object gets `additionalProperties: false`
+//   - every property becomes required, optional ones (with `omitempty` and
+//     without `jsonschema:"required"`) become nullable via
+//     `anyOf: [..., {"type": "null"}]`
+//   - `oneOf` becomes `anyOf`
+//   - unsupported keywords such as `format`, `pattern`, `minimum` or
+//     `maxItems` are dropped, with `format` kept as a hint in the description
+//
+// Nullable fields decode back naturally: `null` sets pointers, slices and maps
+// to nil and leaves other fields at their zero value.
+//
+// Constructs strict mode can't represent (maps, untyped values, `allOf`,
+// conditionals, too many properties or too deep nesting) are reported as a
+// *StrictSchemaError.
+func ToStrictSchema(s *jsonschema.Schema) (*jsonschema.Schema, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &jsonschema.Schema{}
+	if err := json.Unmarshal(b, out); err != nil {
+		return nil, err
+	}
+
+	out.Version = ""
+
+	c := &strictConverter{defs: out.Definitions}
+
+	c.convert(out, "#")
+
+	names := make([]string, 0, len(out.Definitions))
+	for name := range out.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		c.convert(out.Definitions[name], "#/$defs/"+name)
+	}
+
+	root := out
+	if _, def := rootDefinition(out); def != nil {
+		root = def
+	}
+	if root.Type != "object" {
+		c.issue("#", "root must be an object, got %q", root.Type)
+	}
+
+	if c.properties > strictMaxProperties {
+		c.issue("#", "has %d properties in total, at most %d are allowed", c.properties, strictMaxProperties)
+	}
+
+	if depth := c.depth(out, map[string]bool{}); depth > strictMaxDepth {
+		c.issue("#", "objects are nested %d levels deep, at most %d are allowed", depth, strictMaxDepth)
+	}
+
+	if len(c.issues) > 0 {
+		return nil, &StrictSchemaError{Issues: c.issues}
+	}
+
+	return out, nil
+}
+
+type strictConverter struct {
+	defs       jsonschema.Definitions
+	properties int
+	issues     []string
+}
+
+func (c *strictConverter) issue(path string, format string, args ...any) {
+	c.issues = append(c.issues, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *strictConverter) convert(s *jsonschema.Schema, path string) {
+	if s == nil || s.Ref != "" {
+		return
+	}
+
+	if s.Type == "" && s.AnyOf == nil && s.OneOf == nil && s.AllOf == nil && s.Enum == nil && s.Const == nil {
+		c.issue(path, "accepts any value (e.g. interface{}), a concrete type is required")
+		return
+	}
+
+	if s.AllOf != nil {
+		c.issue(path, "allOf is not supported")
+	}
+	if s.Not != nil || s.If != nil || s.Then != nil || s.Else != nil || s.DependentSchemas != nil || s.DependentRequired != nil {
+		c.issue(path, "conditional keywords (not, if/then/else, dependent*) are not supported")
+	}
+	if s.PatternProperties != nil {
+		c.issue(path, "patternProperties is not supported")
+	}
+	if len(s.Enum) > strictMaxEnumValues {
+		c.issue(path, "enum has %d values, at most %d are allowed", len(s.Enum), strictMaxEnumValues)
+	}
+
+	if s.OneOf != nil {
+		s.AnyOf = append(s.AnyOf, s.OneOf...)
+		s.OneOf = nil
+	}
+	for idx, sub := range s.AnyOf {
+		c.convert(sub, fmt.Sprintf("%s/anyOf/%d", path, idx))
+	}
+
+	if s.Format != "" {
+		hint := "Format: " + s.Format
+		if s.Description == "" {
+			s.Description = hint
+		} else {
+			s.Description += " (" + hint + ")"
+		}
+	}
+
+	stripUnsupportedKeywords(s)
+
+	switch s.Type {
+	case "object":
+		if s.AdditionalProperties != nil && !isFalseSchema(s.AdditionalProperties) {
+			c.issue(path, "maps (additionalProperties) are not supported, use a struct or a slice of key/value structs")
+		}
+		s.AdditionalProperties = jsonschema.FalseSchema
+
+		if s.Properties == nil {
+			break
+		}
+
+		required := make(map[string]bool, len(s.Required))
+		for _, name := range s.Required {
+			required[name] = true
+		}
+
+		s.Required = make([]string, 0, s.Properties.Len())
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			c.properties++
+			c.convert(pair.Value, path+"/properties/"+pair.Key)
+
+			if !required[pair.Key] {
+				pair.Value = nullable(pair.Value)
+				s.Properties.Set(pair.Key, pair.Value)
+			}
+			s.Required = append(s.Required, pair.Key)
+		}
+
+	case "array":
+		c.convert(s.Items, path+"/items")
+	}
+}
+
+func stripUnsupportedKeywords(s *jsonschema.Schema) {
+	s.Format = ""
+	s.Pattern = ""
+	s.MinLength, s.MaxLength = nil, nil
+	s.Minimum, s.Maximum = "", ""
+	s.ExclusiveMinimum, s.ExclusiveMaximum = "", ""
+	s.MultipleOf = ""
+	s.MinItems, s.MaxItems = nil, nil
+	s.UniqueItems = false
+	s.Contains, s.MinContains, s.MaxContains = nil, nil, nil
+	s.MinProperties, s.MaxProperties = nil, nil
+	s.PropertyNames = nil
+	s.Default = nil
+	s.Examples = nil
+}
+
+// depth returns the deepest nesting of objects reachable from s, following
+// references once.
+func (c *strictConverter) depth(s *jsonschema.Schema, seen map[string]bool) int {
+	if s == nil {
+		return 0
+	}
+
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/$defs/")
+		if seen[name] {
+			return 0
+		}
+		seen[name] = true
+		defer delete(seen, name)
+		return c.depth(c.defs[name], seen)
+	}
+
+	deepest := 0
+	for _, sub := range s.AnyOf {
+		deepest = max(deepest, c.depth(sub, seen))
+	}
+	deepest = max(deepest, c.depth(s.Items, seen))
+
+	if s.Properties != nil {
+		for pair := s.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			deepest = max(deepest, c.depth(pair.Value, seen))
+		}
+	}
+
+	if s.Type == "object" {
+		deepest++
+	}
+
+	return deepest
+}
+
+func nullable(s *jsonschema.Schema) *jsonschema.Schema {
+	null := &jsonschema.Schema{Type: "null"}
+
+	if s.AnyOf != nil && s.Type == "" {
+		s.AnyOf = append(s.AnyOf, null)
+		return s
+	}
+
+	return &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{s, null},
+	}
+}
+
+func isFalseSchema(s *jsonschema.Schema) bool {
+	b, err := json.Marshal(s)
+	return err == nil && string(b) == "false"
+}
@@ -0,0 +1,588 @@
+package instructor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor/googleai"
+	"github.com/invopop/jsonschema"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+)
+
+// A union is an interface type whose values are one of several registered
+// struct variants. On the wire a union is an object with a discriminator
+// property naming the variant, e.g. `{"kind": "Invoice", "total": 12.5}`.
+type union struct {
+	iface         reflect.Type
+	discriminator string
+	variants      []unionVariant
+}
+
+type unionVariant struct {
+	name string
+	typ  reflect.Type
+}
+
+var unions sync.Map // reflect.Type (interface) -> *union
+
+// RegisterUnion registers the concrete variants of the interface I, so I can be
+// used as a response type or as a field of one:
+//
+//	type Document interface{ isDocument() }
+//
+//	instructor.RegisterUnion[Document]("kind", Invoice{}, Receipt{}, Letter{})
+//
+// Schemas describe I as `oneOf` the variants (a single object with the union of
+// their properties for GoogleAI), each with a required discriminator property
+// holding the variant name: its type name, or InstructorName if it implements
+// ToolNamer. In tool call modes a union response exposes one tool per variant.
+// Responses are decoded into the variant named by the discriminator or by the
+// tool called.
+//
+// Variants must be structs or pointers to structs. Register unions at startup,
+// before the types using them are first extracted.
+func RegisterUnion[I any](discriminator string, variants ...I) error {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		return fmt.Errorf("union %s must be an interface type", iface)
+	}
+	if discriminator == "" {
+		return fmt.Errorf("union %s needs a discriminator property", iface)
+	}
+	if len(variants) == 0 {
+		return fmt.Errorf("union %s needs at least one variant", iface)
+	}
+
+	u := &union{
+		iface:         iface,
+		discriminator: discriminator,
+	}
+
+	for _, variant := range variants {
+		t := reflect.TypeOf(variant)
+		if t == nil || derefType(t).Kind() != reflect.Struct {
+			return fmt.Errorf("variant %v of union %s must be a struct", t, iface)
+		}
+
+		name := sanitizeToolName(derefType(t).Name())
+		if v, ok := newValue(t).(ToolNamer); ok {
+			name = sanitizeToolName(v.InstructorName())
+		}
+		if name == "" {
+			return fmt.Errorf("variant %s of union %s needs a name", t, iface)
+		}
+		if _, ok := u.variant(name); ok {
+			return fmt.Errorf("union %s has more than one variant named %q", iface, name)
+		}
+
+		u.variants = append(u.variants, unionVariant{name: name, typ: t})
+	}
+
+	unions.Store(iface, u)
+
+	// schemas and type checks cached before registration are stale now
+	schemas.reset()
+	enumTypes.Range(func(k, _ any) bool { enumTypes.Delete(k); return true })
+	unionTypes.Range(func(k, _ any) bool { unionTypes.Delete(k); return true })
+
+	return nil
+}
+
+func unionOf(t reflect.Type) *union {
+	if t.Kind() != reflect.Interface {
+		return nil
+	}
+	if u, ok := unions.Load(t); ok {
+		return u.(*union)
+	}
+	return nil
+}
+
+func (u *union) variant(name string) (reflect.Type, bool) {
+	for _, v := range u.variants {
+		if v.name == name {
+			return v.typ, true
+		}
+	}
+	return nil, false
+}
+
+func (u *union) names() []string {
+	names := make([]string, len(u.variants))
+	for i, v := range u.variants {
+		names[i] = v.name
+	}
+	return names
+}
+
+// definitionName is the name of the definition of t under $defs.
+func definitionName(t reflect.Type) string {
+	return typeName(derefType(t))
+}
+
+// reflectSchema generates the JSON schema of t, expanding unions to `oneOf`
+// their variants and adding the variants' definitions.
+func reflectSchema(t reflect.Type) *jsonschema.Schema {
+	var pending []*union
+
+	r := &jsonschema.Reflector{Namer: typeName}
+	r.Mapper = func(t reflect.Type) *jsonschema.Schema {
+		if u := unionOf(t); u != nil {
+			pending = append(pending, u)
+			return unionSchema(u)
+		}
+		return enumSchema(t)
+	}
+
+	schema := r.ReflectFromType(t)
+
+	done := map[*union]bool{}
+
+	// variants may hold unions themselves, which are appended while reflecting
+	for len(pending) > 0 {
+		u := pending[0]
+		pending = pending[1:]
+
+		if done[u] {
+			continue
+		}
+		done[u] = true
+
+		for _, v := range u.variants {
+			reflected := r.ReflectFromType(v.typ)
+
+			if schema.Definitions == nil {
+				schema.Definitions = jsonschema.Definitions{}
+			}
+			for name, def := range reflected.Definitions {
+				if _, ok := schema.Definitions[name]; !ok {
+					schema.Definitions[name] = def
+				}
+			}
+
+			if def, ok := schema.Definitions[definitionName(v.typ)]; ok {
+				addDiscriminator(def, u.discriminator, v.name)
+			}
+		}
+	}
+
+	return schema
+}
+
+func unionSchema(u *union) *jsonschema.Schema {
+	s := &jsonschema.Schema{}
+	for _, v := range u.variants {
+		s.OneOf = append(s.OneOf, &jsonschema.Schema{Ref: "#/$defs/" + definitionName(v.typ)})
+	}
+	return s
+}
+
+// addDiscriminator puts the required discriminator property first in def.
+func addDiscriminator(def *jsonschema.Schema, discriminator, name string) {
+	if def.Properties != nil {
+		if _, ok := def.Properties.Get(discriminator); ok {
+			return
+		}
+	}
+
+	properties := orderedmap.New[string, *jsonschema.Schema]()
+	properties.Set(discriminator, &jsonschema.Schema{
+		Type: "string",
+		Enum: []any{name},
+	})
+	if def.Properties != nil {
+		for pair := def.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			properties.Set(pair.Key, pair.Value)
+		}
+	}
+
+	def.Properties = properties
+	def.Required = append([]string{discriminator}, def.Required...)
+}
+
+// unionFunctions exposes every variant of a union response as its own tool,
+// named after the variant and without the discriminator, which the tool name
+// already tells.
+func unionFunctions(u *union, tSchema *jsonschema.Schema) []FunctionDefinition {
+	fds := make([]FunctionDefinition, 0, len(u.variants))
+
+	for _, v := range u.variants {
+		defName := definitionName(v.typ)
+
+		def, ok := tSchema.Definitions[defName]
+		if !ok {
+			continue
+		}
+
+		properties := orderedmap.New[string, *jsonschema.Schema]()
+		for pair := def.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if pair.Key != u.discriminator {
+				properties.Set(pair.Key, pair.Value)
+			}
+		}
+
+		required := make([]string, 0, len(def.Required))
+		for _, name := range def.Required {
+			if name != u.discriminator {
+				required = append(required, name)
+			}
+		}
+
+		description := def.Description
+		if d, ok := newValue(v.typ).(ToolDescriber); ok {
+			description = d.InstructorDescription()
+		}
+
+		fds = append(fds, FunctionDefinition{
+			Name:        v.name,
+			Description: description,
+			Parameters: &jsonschema.Schema{
+				Type:                 "object",
+				Properties:           properties,
+				Required:             required,
+				AdditionalProperties: def.AdditionalProperties,
+				Definitions:          referencedDefinitions(def, tSchema.Definitions),
+			},
+		})
+	}
+
+	return fds
+}
+
+var definitionRef = regexp.MustCompile(`"#/\$defs/([^"]+)"`)
+
+// referencedDefinitions returns the definitions reachable from s.
+func referencedDefinitions(s *jsonschema.Schema, defs jsonschema.Definitions) jsonschema.Definitions {
+	referenced := jsonschema.Definitions{}
+
+	pending := []*jsonschema.Schema{s}
+	for len(pending) > 0 {
+		b, _ := json.Marshal(pending[0])
+		pending = pending[1:]
+
+		for _, match := range definitionRef.FindAllStringSubmatch(string(b), -1) {
+			name := match[1]
+			if _, ok := referenced[name]; ok {
+				continue
+			}
+			if def, ok := defs[name]; ok {
+				referenced[name] = def
+				pending = append(pending, def)
+			}
+		}
+	}
+
+	if len(referenced) == 0 {
+		return nil
+	}
+	return referenced
+}
+
+// toolArguments returns the arguments of a call to the named tool as the JSON
+// to decode. For union responses the called variant is set as discriminator.
+func (s *Schema) toolArguments(name string, arguments string) string {
+	if s.union == nil {
+		return arguments
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(arguments), &fields); err != nil || fields == nil {
+		return arguments
+	}
+
+	fields[s.union.discriminator], _ = json.Marshal(name)
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return arguments
+	}
+	return string(b)
+}
+
+// googleUnion describes a union for the genai schema generator.
+func googleUnion(t reflect.Type) *googleai.Union {
+	u := unionOf(t)
+	if u == nil {
+		return nil
+	}
+
+	gu := &googleai.Union{
+		Discriminator: u.discriminator,
+		Names:         u.names(),
+	}
+	for _, v := range u.variants {
+		gu.Types = append(gu.Types, v.typ)
+	}
+	return gu
+}
+
+var googleReflector = &googleai.Reflector{
+	Union: googleUnion,
+}
+
+// Decoding
+
+var (
+	unionTypes      sync.Map // reflect.Type -> bool
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// hasUnions reports whether values of t may hold a union, and so can't be
+// decoded by encoding/json alone.
+func hasUnions(t reflect.Type) bool {
+	if has, ok := unionTypes.Load(t); ok {
+		return has.(bool)
+	}
+
+	has := typeHasUnions(t, map[reflect.Type]bool{})
+	unionTypes.Store(t, has)
+	return has
+}
+
+func typeHasUnions(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if unionOf(t) != nil {
+		return true
+	}
+	if visiting[t] || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return false
+	}
+	visiting[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return typeHasUnions(t.Elem(), visiting)
+	case reflect.Map:
+		return typeHasUnions(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if typeHasUnions(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// decodeJSON decodes data into target, a pointer, like json.Unmarshal but
+// resolving unions to their variants.
+func decodeJSON(data []byte, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return json.Unmarshal(data, target)
+	}
+	if !hasUnions(v.Type()) {
+		return json.Unmarshal(data, target)
+	}
+	return decodeValue(data, v.Elem())
+}
+
+func decodeValue(data []byte, v reflect.Value) error {
+	t := v.Type()
+
+	if !hasUnions(t) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		v.Set(reflect.Zero(t))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return decodeUnion(data, v, unionOf(t))
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return decodeValue(data, v.Elem())
+
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+
+	case reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		for i := 0; i < t.Len() && i < len(items); i++ {
+			if err := decodeValue(items[i], v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(t, len(items))
+		for key, item := range items {
+			k := reflect.New(t.Key()).Elem()
+			if t.Key().Kind() == reflect.String {
+				k.SetString(key)
+			} else if err := json.Unmarshal([]byte(key), k.Addr().Interface()); err != nil {
+				return err
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := decodeValue(item, elem); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, elem)
+		}
+		v.Set(m)
+
+	case reflect.Struct:
+		return decodeStruct(data, v)
+
+	default:
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	return nil
+}
+
+func decodeUnion(data []byte, v reflect.Value, u *union) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	raw, ok := fields[u.discriminator]
+	if !ok {
+		return fmt.Errorf("%s: missing discriminator %q, expected one of %s", u.iface, u.discriminator, strings.Join(u.names(), ", "))
+	}
+
+	var name string
+	if err := json.Unmarshal(raw, &name); err != nil {
+		return err
+	}
+
+	variant, ok := u.variant(name)
+	if !ok {
+		return fmt.Errorf("%s: unknown %s %q, expected one of %s", u.iface, u.discriminator, name, strings.Join(u.names(), ", "))
+	}
+
+	value := reflect.New(variant).Elem()
+	if err := decodeValue(data, value); err != nil {
+		return err
+	}
+
+	v.Set(value)
+	return nil
+}
+
+// decodeStruct decodes the fields of a struct one by one, matching keys to
+// fields like encoding/json: exact name first, then case-insensitively.
+func decodeStruct(data []byte, v reflect.Value) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	known := jsonFields(v.Type())
+
+	for key, raw := range fields {
+		field, ok := known[key]
+		if !ok {
+			for name, f := range known {
+				if strings.EqualFold(name, key) {
+					field, ok = f, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		fv, err := fieldByIndexAlloc(v, field)
+		if err != nil {
+			return err
+		}
+		if err := decodeValue(raw, fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonFields maps the JSON names of t's fields to their index, promoting the
+// fields of embedded structs unless shadowed.
+func jsonFields(t reflect.Type) map[string][]int {
+	fields := map[string][]int{}
+
+	var collect func(t reflect.Type, index []int)
+	collect = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+
+			fieldIndex := append(append([]int{}, index...), i)
+
+			if field.Anonymous && name == "" {
+				embedded := derefType(field.Type)
+				if embedded.Kind() == reflect.Struct {
+					collect(embedded, fieldIndex)
+					continue
+				}
+			}
+
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			if existing, ok := fields[name]; !ok || len(existing) > len(fieldIndex) {
+				fields[name] = fieldIndex
+			}
+		}
+	}
+
+	collect(t, nil)
+
+	return fields
+}
+
+// fieldByIndexAlloc returns the field at index, allocating nil embedded
+// pointers on the way.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, errors.New("cannot set embedded pointer to unexported struct " + v.Type().Elem().String())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
@@ -0,0 +1,90 @@
+package instructor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+func TestRunAgent(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		toolCallCompletion("capital", `"{\"result\":\"France\"}"`),
+		toolCallCompletion("StringList", `"{\"result\":[\"Paris\",\"Lyon\"]}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	var asked []string
+	capital := instructor.NewTool("capital", "Look up the capital of a country", func(ctx context.Context, country string) (string, error) {
+		asked = append(asked, country)
+		return "Paris", nil
+	})
+
+	cities, resp, err := instructor.RunAgent[[]string](context.Background(), client, openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Name two French cities, starting with the capital"}},
+	}, 5, capital)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(asked) != 1 || asked[0] != "France" {
+		t.Errorf("tool called with %q", asked)
+	}
+	if len(cities) != 2 || cities[0] != "Paris" {
+		t.Errorf("answer = %q", cities)
+	}
+	if usage := resp.(*openai.ChatCompletionResponse).Usage; usage.TotalTokens != 30 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	messages := (*requests)[1]["messages"].([]any)
+	result := messages[len(messages)-1].(map[string]any)
+	if result["role"] != "tool" || result["content"] != "Paris" || result["tool_call_id"] != "call_1" {
+		t.Errorf("tool result = %v", result)
+	}
+}
+
+func TestRunAgentInvalidAnswer(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		toolCallCompletion("Person", `"{\"name\":42}"`),
+		toolCallCompletion("Person", `"{\"name\":\"Joe\",\"age\":42}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	person, _, err := instructor.RunAgent[Person](context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "Joe" {
+		t.Errorf("got %+v", person)
+	}
+
+	messages := (*requests)[1]["messages"].([]any)
+	if content, _ := messages[len(messages)-1].(map[string]any)["content"].(string); content == "" || content == "Answer accepted." {
+		t.Errorf("invalid answer reported as %q", content)
+	}
+}
+
+func TestRunAgentMaxSteps(t *testing.T) {
+	srv, _ := openaiAPI.start(t,
+		toolCallCompletion("capital", `"{\"result\":\"France\"}"`),
+		toolCallCompletion("capital", `"{\"result\":\"France\"}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	capital := instructor.NewTool("capital", "Look up the capital of a country", func(ctx context.Context, country string) (string, error) {
+		return "Paris", nil
+	})
+
+	_, _, err := instructor.RunAgent[string](context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o}, 2, capital)
+	if !errors.Is(err, instructor.ErrMaxSteps) {
+		t.Errorf("err = %v, want ErrMaxSteps", err)
+	}
+}
@@ -0,0 +1,85 @@
+package instructor_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/liushuangls/go-anthropic/v2"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Weather struct {
+	City string `json:"city"`
+}
+
+func TestAnthropicToolChoice(t *testing.T) {
+	srv, requests := anthropicAPI.start(t,
+		`{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-5-sonnet-20240620","content":[{"type":"tool_use","id":"toolu_1","name":"Person","input":{"name":"Joe","age":42}}],"stop_reason":"tool_use","usage":{"input_tokens":30,"output_tokens":10}}`,
+	)
+
+	client := instructor.FromAnthropic(
+		anthropic.NewClient("key", anthropic.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeToolCall),
+	)
+
+	var person Person
+	resp, err := client.CreateMessages(context.Background(), anthropic.MessagesRequest{
+		Model:     anthropic.ModelClaude3Dot5Sonnet20240620,
+		Messages:  []anthropic.Message{anthropic.NewUserTextMessage("Joe is 42")},
+		MaxTokens: 1024,
+	}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+	if resp.Usage.InputTokens != 30 || resp.Usage.OutputTokens != 10 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	request := (*requests)[0]
+	if want := map[string]any{"type": "tool", "name": "Person"}; !reflect.DeepEqual(request["tool_choice"], want) {
+		t.Errorf("tool_choice = %v, want %v", request["tool_choice"], want)
+	}
+	if _, ok := request["system"]; ok {
+		t.Errorf("system = %v, want none", request["system"])
+	}
+}
+
+func TestAnthropicParallelToolChoice(t *testing.T) {
+	srv, requests := anthropicAPI.start(t,
+		`{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-5-sonnet-20240620","content":[{"type":"tool_use","id":"toolu_1","name":"Person","input":{"name":"Joe","age":42}},{"type":"tool_use","id":"toolu_2","name":"Weather","input":{"city":"Paris"}}],"stop_reason":"tool_use","usage":{"input_tokens":30,"output_tokens":20}}`,
+	)
+
+	client := instructor.FromAnthropic(
+		anthropic.NewClient("key", anthropic.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeToolCall),
+	)
+
+	results, _, err := instructor.CreateParallel(context.Background(), client, anthropic.MessagesRequest{
+		Model:     anthropic.ModelClaude3Dot5Sonnet20240620,
+		Messages:  []anthropic.Message{anthropic.NewUserTextMessage("Who is Joe, and what's the weather in Paris?")},
+		MaxTokens: 1024,
+	}, Person{}, Weather{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if person, ok := results[0].(*Person); !ok || person.Name != "Joe" {
+		t.Errorf("results[0] = %#v", results[0])
+	}
+	if weather, ok := results[1].(*Weather); !ok || weather.City != "Paris" {
+		t.Errorf("results[1] = %#v", results[1])
+	}
+
+	if want := map[string]any{"type": "any"}; !reflect.DeepEqual((*requests)[0]["tool_choice"], want) {
+		t.Errorf("tool_choice = %v, want %v", (*requests)[0]["tool_choice"], want)
+	}
+}
@@ -0,0 +1,64 @@
+package instructor_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+func TestAzureOpenAIDeployment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/prod-gpt4o/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if v := r.URL.Query().Get("api-version"); v != instructor.DefaultAzureAPIVersion {
+			t.Errorf("api-version = %q", v)
+		}
+		if r.Header.Get("Authorization") != "Bearer entra-token" || r.Header.Get("api-key") != "" {
+			t.Errorf("unexpected auth headers %v", r.Header)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"name\":\"Joe\",\"age\":42}"}}],"usage":{"prompt_tokens":30,"completion_tokens":10,"total_tokens":40}}`)
+	}))
+	defer srv.Close()
+
+	client := instructor.FromAzureOpenAI(instructor.AzureConfig{
+		Endpoint:    srv.URL,
+		Deployments: map[string]string{openai.GPT4o: "prod-gpt4o"},
+		Token: func(ctx context.Context) (string, error) {
+			return "entra-token", nil
+		},
+	}, instructor.WithMode(instructor.ModeStructuredOutputs))
+
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+	}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+}
+
+func TestAzureOpenAIModeNotSupported(t *testing.T) {
+	err := instructor.FromAzureOpenAI(instructor.AzureConfig{
+		Endpoint:   "https://example.openai.azure.com",
+		APIVersion: "2024-06-01",
+		APIKey:     "key",
+	}, instructor.WithMode(instructor.ModeJSONStrict)).Err()
+
+	if err == nil || !strings.Contains(err.Error(), "requires Azure OpenAI api-version 2024-08-01-preview") {
+		t.Fatalf("got %v, want the api-version error", err)
+	}
+}
@@ -0,0 +1,169 @@
+package instructor_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+// bedrockClient returns a client for a fake endpoint answering with handler.
+func bedrockClient(t *testing.T, handler http.HandlerFunc) *bedrockruntime.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return bedrockruntime.New(bedrockruntime.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	})
+}
+
+func TestBedrockToolCall(t *testing.T) {
+	var request map[string]any
+
+	client := instructor.FromBedrock(
+		bedrockClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/model/anthropic.claude-3-haiku/converse" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") {
+				t.Errorf("request not signed: %q", auth)
+			}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Errorf("decoding request: %v", err)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{
+				"output": {"message": {"role": "assistant", "content": [{"toolUse": {"toolUseId": "t1", "name": "Person", "input": {"name": "Joe", "age": 42}}}]}},
+				"stopReason": "tool_use",
+				"usage": {"inputTokens": 30, "outputTokens": 10, "totalTokens": 40}
+			}`)
+		}),
+		instructor.WithMode(instructor.ModeToolCall),
+	)
+
+	var person Person
+	resp, err := client.Converse(context.Background(), &bedrockruntime.ConverseInput{
+		ModelId: aws.String("anthropic.claude-3-haiku"),
+		Messages: []types.Message{{
+			Role:    types.ConversationRoleUser,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: "Joe is 42"}},
+		}},
+	}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+	if aws.ToInt32(resp.Usage.TotalTokens) != 40 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	config, _ := request["toolConfig"].(map[string]any)
+	choice, _ := config["toolChoice"].(map[string]any)
+	if tool, _ := choice["tool"].(map[string]any); tool["name"] != "Person" {
+		t.Errorf("tool choice not forced: %v", config["toolChoice"])
+	}
+}
+
+func TestBedrockJSONTruncated(t *testing.T) {
+	client := instructor.FromBedrock(
+		bedrockClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{
+				"output": {"message": {"role": "assistant", "content": [{"text": "{\"name\":"}]}},
+				"stopReason": "max_tokens",
+				"usage": {"inputTokens": 30, "outputTokens": 10, "totalTokens": 40}
+			}`)
+		}),
+		instructor.WithMode(instructor.ModeJSON),
+	)
+
+	var person Person
+	resp, err := client.Converse(context.Background(), &bedrockruntime.ConverseInput{
+		ModelId: aws.String("meta.llama3-70b-instruct-v1:0"),
+	}, &person)
+
+	var truncated *instructor.TruncationError
+	if !errors.As(err, &truncated) {
+		t.Fatalf("got %v, want a truncation error", err)
+	}
+	if aws.ToInt32(resp.Usage.InputTokens) != 30 {
+		t.Errorf("usage lost: %+v", resp.Usage)
+	}
+}
+
+func TestBedrockStream(t *testing.T) {
+	chunks := []string{`{"items": [`, `{"name":"Ann","age":30},`, `{"name":"Bob",`, `"age":40}`, `]}`}
+
+	client := instructor.FromBedrock(
+		bedrockClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/model/anthropic.claude-3-haiku/converse-stream" {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			io.Copy(io.Discard, r.Body)
+
+			w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+
+			encoder := eventstream.NewEncoder()
+			var body bytes.Buffer
+			for _, chunk := range chunks {
+				payload, _ := json.Marshal(map[string]any{"contentBlockIndex": 0, "delta": map[string]any{"text": chunk}})
+				encodeBedrockEvent(t, encoder, &body, "contentBlockDelta", payload)
+			}
+			encodeBedrockEvent(t, encoder, &body, "messageStop", []byte(`{"stopReason":"end_turn"}`))
+			w.Write(body.Bytes())
+		}),
+		instructor.WithMode(instructor.ModeJSON),
+	)
+
+	stream, err := client.ConverseStream(context.Background(), &bedrockruntime.ConverseStreamInput{
+		ModelId: aws.String("anthropic.claude-3-haiku"),
+	}, *new(Person))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for item := range stream {
+		names = append(names, item.(*Person).Name)
+	}
+
+	if fmt.Sprint(names) != "[Ann Bob]" {
+		t.Errorf("got %v", names)
+	}
+}
+
+func encodeBedrockEvent(t *testing.T, encoder *eventstream.Encoder, w io.Writer, eventType string, payload []byte) {
+	t.Helper()
+
+	var headers eventstream.Headers
+	headers.Set(":message-type", eventstream.StringValue("event"))
+	headers.Set(":event-type", eventstream.StringValue(eventType))
+	headers.Set(":content-type", eventstream.StringValue("application/json"))
+
+	if err := encoder.Encode(w, eventstream.Message{Headers: headers, Payload: payload}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,161 @@
+package instructor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+func TestResponseCache(t *testing.T) {
+	config, requests := compatibleServer(t, personCompletion)
+
+	cache := instructor.NewResponseCache(instructor.NewMemoryCache(10), 0)
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(config),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithCache(cache),
+	)
+
+	request := openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Joe is 42"}},
+	}
+
+	for _, ctx := range []context.Context{context.Background(), context.Background(), instructor.SkipCache(context.Background())} {
+		var person Person
+		if _, err := client.CreateChatCompletion(ctx, request, &person); err != nil {
+			t.Fatal(err)
+		}
+		if person.Name != "Joe" || person.Age != 42 {
+			t.Errorf("got %+v", person)
+		}
+	}
+
+	if len(*requests) != 2 {
+		t.Errorf("sent %d requests, want 2", len(*requests))
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	// a request for another model misses
+	request.Model = openai.GPT4oMini
+	var person Person
+	if _, err := client.CreateChatCompletion(context.Background(), request, &person); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 3 {
+		t.Errorf("sent %d requests, want 3", len(*requests))
+	}
+}
+
+func TestResponseCacheEndpoints(t *testing.T) {
+	cache := instructor.NewResponseCache(instructor.NewMemoryCache(10), 0)
+
+	vllm, vllmRequests := compatibleServer(t, personCompletion)
+	groq, groqRequests := compatibleServer(t, personCompletion)
+
+	for _, client := range []*instructor.InstructorOpenAI{
+		instructor.FromOpenAICompatible(vllm, instructor.ProfileVLLM, instructor.WithMode(instructor.ModeJSON), instructor.WithCache(cache)),
+		instructor.FromOpenAICompatible(groq, instructor.ProfileGroq, instructor.WithMode(instructor.ModeJSON), instructor.WithCache(cache)),
+	} {
+		var person Person
+		if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+			Model: "llama-3.1-8b",
+		}, &person); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(*vllmRequests) != 1 || len(*groqRequests) != 1 {
+		t.Errorf("sent %d requests to vLLM and %d to Groq, want 1 each", len(*vllmRequests), len(*groqRequests))
+	}
+}
+
+func TestResponseCacheBaseURLs(t *testing.T) {
+	cache := instructor.NewResponseCache(instructor.NewMemoryCache(10), 0)
+
+	first, firstRequests := compatibleServer(t, personCompletion)
+	second, secondRequests := compatibleServer(t, personCompletion)
+
+	// plain OpenAI clients pointed at different servers don't share responses
+	for _, config := range []openai.ClientConfig{first, second} {
+		client := instructor.FromOpenAI(openai.NewClientWithConfig(config), instructor.WithMode(instructor.ModeJSON), instructor.WithCache(cache))
+
+		var person Person
+		if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+			Model: openai.GPT4o,
+		}, &person); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(*firstRequests) != 1 || len(*secondRequests) != 1 {
+		t.Errorf("sent %d and %d requests, want 1 each", len(*firstRequests), len(*secondRequests))
+	}
+}
+
+func TestResponseCacheUnhashableRequest(t *testing.T) {
+	config, _ := compatibleServer(t, personCompletion)
+
+	cache := instructor.NewResponseCache(instructor.NewMemoryCache(10), 0)
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(config), instructor.WithMode(instructor.ModeJSON), instructor.WithCache(cache))
+
+	// a request that can't be encoded can't be hashed into a key either
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:      openai.GPT4o,
+		ToolChoice: func() {},
+	}, &person)
+	if err == nil {
+		t.Fatal("no error")
+	}
+
+	if stats := cache.Stats(); stats.Errors != 1 {
+		t.Errorf("stats = %+v, want the key error counted", stats)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := instructor.NewMemoryCache(2)
+
+	cache.Set(ctx, "a", []byte("1"), 0)
+	cache.Set(ctx, "b", []byte("2"), 0)
+	cache.Get(ctx, "a")
+	cache.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Errorf("least recently used entry kept")
+	}
+	if value, ok, _ := cache.Get(ctx, "a"); !ok || string(value) != "1" {
+		t.Errorf("a = %q, %t", value, ok)
+	}
+
+	cache.Set(ctx, "d", []byte("4"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok, _ := cache.Get(ctx, "d"); ok {
+		t.Errorf("expired entry returned")
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	ctx := context.Background()
+	cache, err := instructor.NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.Set(ctx, "key", []byte(`{"name":"Joe"}`), 0); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok, err := cache.Get(ctx, "key"); err != nil || !ok || string(value) != `{"name":"Joe"}` {
+		t.Errorf("got %q, %t, %v", value, ok, err)
+	}
+	if _, ok, err := cache.Get(ctx, "missing"); err != nil || ok {
+		t.Errorf("missing entry found: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package instructor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cohere "github.com/cohere-ai/cohere-go/v2"
+	cohereclient "github.com/cohere-ai/cohere-go/v2/client"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+func TestModeAuto(t *testing.T) {
+	cohere := instructor.FromCohere(cohereclient.NewClient(), instructor.WithMode(instructor.ModeAuto))
+	if cohere.Mode() != instructor.ModeToolCall || cohere.StreamMode() != instructor.ModeJSON {
+		t.Errorf("Cohere picked %s and %s for streams", cohere.Mode(), cohere.StreamMode())
+	}
+
+	config, _ := compatibleServer(t, `{}`)
+	groq := instructor.FromOpenAICompatible(config, instructor.ProfileGroq, instructor.WithMode(instructor.ModeAuto))
+	if groq.Mode() != instructor.ModeToolCall || groq.StreamMode() != instructor.ModeToolCall {
+		t.Errorf("Groq picked %s and %s for streams", groq.Mode(), groq.StreamMode())
+	}
+	if groq.ModeCapabilities().Supports(instructor.ModeStructuredOutputs, false) {
+		t.Errorf("Groq reported to support structured outputs")
+	}
+
+	// without a mode, clients use ModeDefault if they can
+	if mode := instructor.FromOpenAI(openai.NewClientWithConfig(config)).Mode(); mode != instructor.ModeDefault {
+		t.Errorf("OpenAI picked %s", mode)
+	}
+	if mode := instructor.FromCohere(cohereclient.NewClient()).Mode(); mode != instructor.ModeToolCall {
+		t.Errorf("Cohere picked %s", mode)
+	}
+}
+
+func TestModeAutoModel(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		toolCallCompletion("Person", `"{\"name\":\"Joe\",\"age\":42}"`),
+		personCompletion,
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeAuto))
+	if client.Mode() != instructor.ModeToolCall {
+		t.Fatalf("picked %s", client.Mode())
+	}
+
+	for _, model := range []string{openai.GPT4o, "o1-preview"} {
+		var person Person
+		if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: model}, &person); err != nil {
+			t.Fatalf("%s: %v", model, err)
+		}
+		if person.Name != "Joe" {
+			t.Errorf("%s: person = %+v", model, person)
+		}
+	}
+
+	// o1-preview doesn't support tool calls, so the schema goes in the prompt
+	if _, ok := (*requests)[0]["tools"]; !ok {
+		t.Errorf("no tools sent to gpt-4o")
+	}
+	if _, ok := (*requests)[1]["tools"]; ok {
+		t.Errorf("tools sent to o1-preview")
+	}
+}
+
+func TestModeNotSupported(t *testing.T) {
+	client := instructor.FromCohere(cohereclient.NewClient(), instructor.WithMode(instructor.ModeJSONSchema))
+	err := client.Err()
+	if err == nil || !strings.Contains(err.Error(), "mode 'json_schema_mode' is not supported for Cohere") {
+		t.Errorf("got %v, want the unsupported mode error", err)
+	}
+
+	// requests fail with it instead of reaching the API
+	var person Person
+	if _, err := client.Chat(context.Background(), &cohere.ChatRequest{Message: "Joe is 42"}, &person); err != client.Err() {
+		t.Errorf("request failed with %v, want %v", err, client.Err())
+	}
+
+	// supported for synchronous calls only
+	cohere := instructor.FromCohere(cohereclient.NewClient(), instructor.WithMode(instructor.ModeToolCall))
+	if cohere.StreamMode() != instructor.ModeToolCall {
+		t.Errorf("stream mode = %s", cohere.StreamMode())
+	}
+}
+
+func TestModelModes(t *testing.T) {
+	tests := []struct {
+		provider instructor.Provider
+		model    string
+		mode     instructor.Mode
+		stream   bool
+		want     bool
+	}{
+		{instructor.ProviderOpenAI, "gpt-4o-mini", instructor.ModeStructuredOutputs, true, true},
+		{instructor.ProviderOpenAI, "gpt-4o-2024-05-13", instructor.ModeStructuredOutputs, false, false},
+		{instructor.ProviderOpenAI, "gpt-4-turbo", instructor.ModeToolCallStrict, false, false},
+		{instructor.ProviderOpenAI, "gpt-4-turbo", instructor.ModeToolCall, true, true},
+		{instructor.ProviderOpenAI, "o1-mini-2024-09-12", instructor.ModeJSONSchema, true, false},
+		{instructor.ProviderAnthropic, "claude-2.1", instructor.ModeToolCall, false, false},
+		{instructor.ProviderAnthropic, "claude-3-5-sonnet-20240620", instructor.ModeToolCall, false, true},
+		{instructor.ProviderCohere, "command-r-plus", instructor.ModeToolCall, true, false},
+		{instructor.ProviderMistral, "open-mistral-7b", instructor.ModeToolCall, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := instructor.ModelModes(tt.provider, tt.model).Supports(tt.mode, tt.stream); got != tt.want {
+			t.Errorf("%s %s supports %s (stream %t) = %t", tt.provider, tt.model, tt.mode, tt.stream, got)
+		}
+	}
+}
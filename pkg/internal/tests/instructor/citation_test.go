@@ -0,0 +1,146 @@
+package instructor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+const contract = "The supplier may terminate this agreement with 30 days notice. Late payments incur a 5% fee."
+
+type Finding struct {
+	Risks []instructor.Cited[string] `json:"risks"`
+}
+
+// findingCompletion is a completion answering with a risk backed by quote.
+func findingCompletion(quote string) string {
+	return `{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"risks\":[{\"value\":\"termination\",\"quotes\":[\"` + quote + `\"]}]}"}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`
+}
+
+func findingClient(t *testing.T, bodies ...string) (*instructor.InstructorOpenAI, *[]map[string]any) {
+	srv, requests := openaiAPI.start(t, bodies...)
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(1),
+		instructor.WithCache(instructor.NewResponseCache(instructor.NewMemoryCache(10), 0)),
+	)
+	return client, requests
+}
+
+var findingRequest = openai.ChatCompletionRequest{
+	Model:    openai.GPT4o,
+	Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "List the risks of the contract"}},
+}
+
+func TestCitationFuzzyMatch(t *testing.T) {
+	client, _ := findingClient(t, findingCompletion("the supplier may  terminate this agreement with 30 days' notice"))
+
+	var finding Finding
+	if _, err := client.CreateChatCompletion(instructor.WithCitationSource(context.Background(), contract), findingRequest, &finding); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := finding.Risks[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if quoted := contract[spans[0].Start:spans[0].End]; quoted != "The supplier may terminate this agreement with 30 days notice" {
+		t.Errorf("span covers %q", quoted)
+	}
+	if spans[0].Similarity >= 1 || spans[0].Similarity < instructor.MinCitationSimilarity {
+		t.Errorf("similarity = %v", spans[0].Similarity)
+	}
+}
+
+func TestCitationReask(t *testing.T) {
+	client, requests := findingClient(t,
+		findingCompletion("The buyer may cancel at any time"),
+		findingCompletion("terminate this agreement with 30 days notice"),
+	)
+
+	var finding Finding
+	if _, err := client.CreateChatCompletion(instructor.WithCitationSource(context.Background(), contract), findingRequest, &finding); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(*requests))
+	}
+	messages := (*requests)[1]["messages"].([]any)
+	feedback := messages[len(messages)-1].(map[string]any)["content"].(string)
+	if !strings.Contains(feedback, `quote "The buyer may cancel at any time" was not found in the source`) {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestCitationRetriesExhausted(t *testing.T) {
+	client, _ := findingClient(t,
+		findingCompletion("The buyer may cancel at any time"),
+		findingCompletion("The buyer may cancel at any time"),
+	)
+
+	var finding Finding
+	_, err := client.CreateChatCompletion(instructor.WithCitationSource(context.Background(), contract), findingRequest, &finding)
+
+	var citationErr *instructor.CitationError
+	if !errors.As(err, &citationErr) {
+		t.Fatalf("err = %v, want a CitationError", err)
+	}
+	if citationErr.Path != "$.risks[0]" {
+		t.Errorf("path = %q", citationErr.Path)
+	}
+}
+
+func TestCitationDropUncitedFacts(t *testing.T) {
+	client, requests := findingClient(t, findingCompletion("The buyer may cancel at any time"))
+
+	ctx := instructor.DropUncitedFacts(instructor.WithCitationSource(context.Background(), contract))
+
+	var finding Finding
+	if _, err := client.CreateChatCompletion(ctx, findingRequest, &finding); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(finding.Risks) != 0 {
+		t.Errorf("kept %+v", finding.Risks)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("sent %d requests, want 1", len(*requests))
+	}
+}
+
+func TestCitationCache(t *testing.T) {
+	client, requests := findingClient(t,
+		findingCompletion("Late payments incur a 5% fee"),
+		findingCompletion("Late fees are 10%"),
+	)
+
+	ctx := instructor.WithCitationSource(context.Background(), contract)
+
+	for range 2 {
+		var finding Finding
+		if _, err := client.CreateChatCompletion(ctx, findingRequest, &finding); err != nil {
+			t.Fatal(err)
+		}
+		if len(finding.Risks) != 1 || len(finding.Risks[0].Spans) != 1 {
+			t.Fatalf("got %+v, want a risk with its span", finding.Risks)
+		}
+	}
+	if len(*requests) != 1 {
+		t.Errorf("sent %d requests, want 1", len(*requests))
+	}
+
+	// the same request about another source isn't answered from the cache
+	var finding Finding
+	if _, err := client.CreateChatCompletion(instructor.WithCitationSource(context.Background(), "Late fees are 10%."), findingRequest, &finding); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("sent %d requests, want 2", len(*requests))
+	}
+}
@@ -0,0 +1,175 @@
+package instructor_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/liushuangls/go-anthropic/v2"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+// roles returns the role of every message of a recorded request.
+func roles(request map[string]any) []string {
+	var roles []string
+	for _, m := range request["messages"].([]any) {
+		roles = append(roles, m.(map[string]any)["role"].(string))
+	}
+	return roles
+}
+
+func TestConversation(t *testing.T) {
+	srv, requests := openaiAPI.start(t, personCompletion, ticketCompletion("billing"))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSON))
+
+	conversation := instructor.NewConversation(client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+
+	person, _, err := instructor.Ask[Person](context.Background(), conversation, "Joe is 42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("person = %+v", person)
+	}
+
+	ticket, _, err := instructor.Ask[Ticket](context.Background(), conversation, "He was charged twice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Label != "billing" {
+		t.Errorf("ticket = %+v", ticket)
+	}
+
+	// the schema prompt is sent once per request, for the type asked for
+	second := (*requests)[1]
+	if got, want := roles(second), []string{"system", "user", "assistant", "user"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("roles = %v, want %v", got, want)
+	}
+	messages := second["messages"].([]any)
+	if system := messages[0].(map[string]any)["content"].(string); !strings.Contains(system, "tech_issue") || strings.Contains(system, `"age"`) {
+		t.Errorf("system = %s", system)
+	}
+	if reply := messages[2].(map[string]any)["content"]; reply != `{"name":"Joe","age":42}` {
+		t.Errorf("recorded reply = %v", reply)
+	}
+
+	// the history has no schema prompt of its own
+	history := conversation.Request().(openai.ChatCompletionRequest).Messages
+	var got []string
+	for _, m := range history {
+		got = append(got, m.Role)
+	}
+	if want := []string{"user", "assistant", "user", "assistant"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("history roles = %v, want %v", got, want)
+	}
+}
+
+func TestConversationToolCalls(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		toolCallCompletion("Person", `"{\"name\":\"Joe\",\"age\":42}"`),
+		toolCallCompletion("Ticket", `"{\"label\":\"billing\"}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	conversation := instructor.NewConversation(client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+
+	if _, _, err := instructor.Ask[Person](context.Background(), conversation, "Joe is 42"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := instructor.Ask[Ticket](context.Background(), conversation, "He was charged twice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tool call is recorded and answered
+	second := (*requests)[1]
+	if got, want := roles(second), []string{"user", "assistant", "tool", "user"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("roles = %v, want %v", got, want)
+	}
+	messages := second["messages"].([]any)
+	calls := messages[1].(map[string]any)["tool_calls"].([]any)
+	if id := calls[0].(map[string]any)["id"]; id != "call_1" {
+		t.Errorf("tool call id = %v", id)
+	}
+	result := messages[2].(map[string]any)
+	if result["tool_call_id"] != "call_1" || result["content"] != "Recorded." {
+		t.Errorf("tool result = %v", result)
+	}
+}
+
+func TestConversationErrorKeepsHistory(t *testing.T) {
+	srv, requests := openaiAPI.start(t, personCompletion)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(0),
+	)
+
+	conversation := instructor.NewConversation(client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+
+	if _, _, err := instructor.Ask[Person](context.Background(), conversation, "Joe is 42"); err != nil {
+		t.Fatal(err)
+	}
+	before := conversation.Request()
+
+	if _, _, err := instructor.Ask[Ticket](context.Background(), conversation, "He was charged twice"); err == nil {
+		t.Fatal("no error")
+	}
+	if !reflect.DeepEqual(conversation.Request(), before) {
+		t.Errorf("history changed to %+v", conversation.Request())
+	}
+
+	// asking again starts from the same history
+	instructor.Ask[Ticket](context.Background(), conversation, "He was charged twice")
+	if got, want := roles((*requests)[2]), roles((*requests)[1]); !reflect.DeepEqual(got, want) {
+		t.Errorf("roles = %v, want %v", got, want)
+	}
+}
+
+func TestConversationAnthropic(t *testing.T) {
+	srv, requests := anthropicAPI.start(t,
+		`{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"tool_use","id":"toolu_1","name":"Person","input":{"name":"Joe","age":42}}],"stop_reason":"tool_use","usage":{"input_tokens":30,"output_tokens":10}}`,
+		`{"id":"msg_2","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"tool_use","id":"toolu_2","name":"Ticket","input":{"label":"billing"}}],"stop_reason":"tool_use","usage":{"input_tokens":40,"output_tokens":10}}`,
+	)
+
+	client := instructor.FromAnthropic(
+		anthropic.NewClient("key", anthropic.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeToolCall),
+	)
+
+	conversation := instructor.NewConversation(client, anthropic.MessagesRequest{
+		Model:     anthropic.ModelClaude3Haiku20240307,
+		MaxTokens: 1024,
+	})
+
+	if _, _, err := instructor.Ask[Person](context.Background(), conversation, "Joe is 42"); err != nil {
+		t.Fatal(err)
+	}
+	ticket, _, err := instructor.Ask[Ticket](context.Background(), conversation, "He was charged twice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Label != "billing" {
+		t.Errorf("ticket = %+v", ticket)
+	}
+
+	// roles alternate: the next question joins the tool result
+	second := (*requests)[1]
+	if got, want := roles(second), []string{"user", "assistant", "user"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("roles = %v, want %v", got, want)
+	}
+	content := second["messages"].([]any)[2].(map[string]any)["content"].([]any)
+	if len(content) != 2 {
+		t.Fatalf("content = %v", content)
+	}
+	if result := content[0].(map[string]any); result["type"] != "tool_result" || result["tool_use_id"] != "toolu_1" {
+		t.Errorf("tool result = %v", result)
+	}
+	if question := content[1].(map[string]any); question["type"] != "text" || question["text"] != "He was charged twice" {
+		t.Errorf("question = %v", question)
+	}
+}
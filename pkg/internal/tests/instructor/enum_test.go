@@ -0,0 +1,171 @@
+package instructor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Label string
+
+func (Label) Enum() []any { return []any{"billing", "tech_issue"} }
+
+type Ticket struct {
+	Label Label `json:"label"`
+}
+
+// ticketCompletion is a completion answering with a ticket labelled label.
+func ticketCompletion(label string) string {
+	return `{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"label\":\"` + label + `\"}"}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`
+}
+
+func TestEnumSchema(t *testing.T) {
+	srv, requests := openaiAPI.start(t, ticketCompletion("billing"))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSONSchema))
+
+	var ticket Ticket
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &ticket); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := (*requests)[0]["messages"].([]any)
+	system := messages[0].(map[string]any)["content"].(string)
+	if !strings.Contains(system, `"enum"`) || !strings.Contains(system, `"tech_issue"`) {
+		t.Errorf("schema has no enum:\n%s", system)
+	}
+}
+
+func TestEnumReask(t *testing.T) {
+	srv, requests := openaiAPI.start(t, ticketCompletion("refund"), ticketCompletion("billing"))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSON))
+
+	var ticket Ticket
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "I want my money back"}},
+	}, &ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ticket.Label != "billing" {
+		t.Errorf("label = %q, want billing", ticket.Label)
+	}
+	if resp.Usage.TotalTokens != 30 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(*requests))
+	}
+	messages := (*requests)[1]["messages"].([]any)
+	feedback := messages[len(messages)-1].(map[string]any)["content"].(string)
+	if !strings.Contains(feedback, `"refund" is not one of "billing", "tech_issue"`) {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestEnumRetriesExhausted(t *testing.T) {
+	srv, _ := openaiAPI.start(t, ticketCompletion("refund"), ticketCompletion("refund"))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(1),
+	)
+
+	var ticket Ticket
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &ticket)
+
+	var enumErr *instructor.EnumError
+	if !errors.As(err, &enumErr) {
+		t.Fatalf("err = %v, want an EnumError", err)
+	}
+	if enumErr.Value != Label("refund") {
+		t.Errorf("value = %v", enumErr.Value)
+	}
+}
+
+type Escalation struct {
+	Label *Label `json:"label,omitempty"`
+}
+
+func TestEnumPointer(t *testing.T) {
+	srv, _ := openaiAPI.start(t,
+		contentCompletion(`"{}"`),
+		contentCompletion(`"{\"label\":\"billing\"}"`),
+		contentCompletion(`"{\"label\":\"refund\"}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(0),
+	)
+
+	var missing Escalation
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &missing); err != nil {
+		t.Fatal(err)
+	}
+	if missing.Label != nil {
+		t.Errorf("label = %v", *missing.Label)
+	}
+
+	var valid Escalation
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &valid); err != nil {
+		t.Fatal(err)
+	}
+	if valid.Label == nil || *valid.Label != "billing" {
+		t.Errorf("label = %v", valid.Label)
+	}
+
+	var invalid Escalation
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &invalid)
+
+	var enumErr *instructor.EnumError
+	if !errors.As(err, &enumErr) || enumErr.Path != "$.label" {
+		t.Errorf("err = %v, want an EnumError for $.label", err)
+	}
+}
+
+func TestEnumMaybe(t *testing.T) {
+	srv, _ := openaiAPI.start(t,
+		contentCompletion(`"{\"error\":true,\"message\":\"no label fits\"}"`),
+		contentCompletion(`"{\"result\":\"tech_issue\",\"error\":false}"`),
+		contentCompletion(`"{\"result\":\"refund\",\"error\":false}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(0),
+	)
+
+	var missing instructor.Maybe[Label]
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &missing); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, reason := missing.Unwrap(); found || reason != "no label fits" {
+		t.Errorf("unwrapped %v, %q", found, reason)
+	}
+
+	var found instructor.Maybe[Label]
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &found); err != nil {
+		t.Fatal(err)
+	}
+	if label, ok, _ := found.Unwrap(); !ok || label != "tech_issue" {
+		t.Errorf("unwrapped %v, %v", label, ok)
+	}
+
+	var invalid instructor.Maybe[Label]
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &invalid)
+
+	var enumErr *instructor.EnumError
+	if !errors.As(err, &enumErr) || enumErr.Path != "$.result" {
+		t.Errorf("err = %v, want an EnumError for $.result", err)
+	}
+}
@@ -0,0 +1,80 @@
+package instructor_test
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+// contentCompletion is a completion answering with content, a JSON string.
+func contentCompletion(content string) string {
+	return `{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":` + content + `}}]}`
+}
+
+func TestEnvelopeToolCall(t *testing.T) {
+	srv, requests := openaiAPI.start(t, toolCallCompletion("StringList", `"{\"result\":[\"Paris\",\"Lyon\"]}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	var cities []string
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &cities); err != nil {
+		t.Fatal(err)
+	}
+	if len(cities) != 2 || cities[1] != "Lyon" {
+		t.Errorf("got %q", cities)
+	}
+
+	tools := (*requests)[0]["tools"].([]any)
+	function := tools[0].(map[string]any)["function"].(map[string]any)
+	if function["name"] != "StringList" {
+		t.Errorf("tool = %v", function["name"])
+	}
+	properties := function["parameters"].(map[string]any)["properties"].(map[string]any)
+	if result, _ := properties["result"].(map[string]any); result["type"] != "array" {
+		t.Errorf("parameters = %v, want the array under result", properties)
+	}
+}
+
+func TestEnvelopeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"envelope", `"{\"result\":{\"Paris\":2100000,\"Lyon\":520000}}"`},
+		// models sometimes drop the envelope
+		{"bare", `"{\"Paris\":2100000,\"Lyon\":520000}"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := openaiAPI.start(t, contentCompletion(tt.content))
+
+			client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSON))
+
+			var population map[string]int
+			if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &population); err != nil {
+				t.Fatal(err)
+			}
+			if population["Lyon"] != 520000 {
+				t.Errorf("got %v", population)
+			}
+		})
+	}
+}
+
+func TestEnvelopeScalar(t *testing.T) {
+	srv, _ := openaiAPI.start(t, contentCompletion(`"{\"result\":42}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSON))
+
+	var age *int
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &age); err != nil {
+		t.Fatal(err)
+	}
+	if age == nil || *age != 42 {
+		t.Errorf("got %v", age)
+	}
+}
@@ -0,0 +1,123 @@
+package instructor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/liushuangls/go-anthropic/v2"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+const truncatedCompletion = `{"choices":[{"index":0,"finish_reason":"length","message":{"role":"assistant","content":"{\"name\":\"Jo"}}],"usage":{"prompt_tokens":30,"completion_tokens":4,"total_tokens":34}}`
+
+func TestTruncationError(t *testing.T) {
+	srv, requests := openaiAPI.start(t, truncatedCompletion)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSON))
+
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o, MaxTokens: 4}, &person)
+
+	var truncated *instructor.TruncationError
+	if !errors.As(err, &truncated) || truncated.Reason != "length" {
+		t.Fatalf("err = %v, want a TruncationError", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("sent %d requests, truncation isn't retried by default", len(*requests))
+	}
+}
+
+func TestTruncationRetries(t *testing.T) {
+	srv, requests := openaiAPI.start(t, truncatedCompletion, personCompletion)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(0),
+		instructor.WithTruncationRetries(1),
+	)
+
+	var person Person
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o, MaxTokens: 4}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "Joe" {
+		t.Errorf("got %+v", person)
+	}
+	if resp.Usage.TotalTokens != 74 {
+		t.Errorf("usage = %+v, want both responses counted", resp.Usage)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(*requests))
+	}
+	if maxTokens := (*requests)[1]["max_tokens"]; maxTokens != float64(8) {
+		t.Errorf("max_tokens = %v, want it doubled", maxTokens)
+	}
+}
+
+func TestTruncationRetriesWithoutMaxTokens(t *testing.T) {
+	srv, requests := openaiAPI.start(t, truncatedCompletion)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithTruncationRetries(1),
+	)
+
+	// the model's own limit was hit, which can't be raised
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &person)
+
+	var truncated *instructor.TruncationError
+	if !errors.As(err, &truncated) {
+		t.Fatalf("err = %v, want a TruncationError", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("sent %d requests, want 1", len(*requests))
+	}
+}
+
+func TestContentFilterError(t *testing.T) {
+	srv, _ := openaiAPI.start(t,
+		`{"choices":[{"index":0,"finish_reason":"content_filter","message":{"role":"assistant","content":""}}]}`,
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSON))
+
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &person)
+
+	var filtered *instructor.ContentFilterError
+	if !errors.As(err, &filtered) || filtered.Provider != instructor.ProviderOpenAI {
+		t.Fatalf("err = %v, want a ContentFilterError", err)
+	}
+}
+
+func TestAnthropicTruncationError(t *testing.T) {
+	srv, _ := anthropicAPI.start(t,
+		`{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-haiku-20240307","content":[{"type":"text","text":"{\"name\":\"Jo"}],"stop_reason":"max_tokens","usage":{"input_tokens":30,"output_tokens":4}}`,
+	)
+
+	client := instructor.FromAnthropic(
+		anthropic.NewClient("key", anthropic.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeJSONSchema),
+	)
+
+	var person Person
+	resp, err := client.CreateMessages(context.Background(), anthropic.MessagesRequest{
+		Model:     anthropic.ModelClaude3Haiku20240307,
+		Messages:  []anthropic.Message{anthropic.NewUserTextMessage("Joe is 42")},
+		MaxTokens: 4,
+	}, &person)
+
+	var truncated *instructor.TruncationError
+	if !errors.As(err, &truncated) || truncated.Provider != instructor.ProviderAnthropic {
+		t.Fatalf("err = %v, want a TruncationError", err)
+	}
+	if resp.Usage.OutputTokens != 4 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
@@ -0,0 +1,122 @@
+package instructor_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Audit struct {
+	CreatedBy string    `json:"created_by" jsonschema:"description=Who created the record"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Shipment struct {
+	Audit
+
+	Status   string            `json:"status" jsonschema:"enum=pending,enum=shipped"`
+	Carrier  string            `json:"carrier,omitempty" jsonschema:"description=Name of the carrier"`
+	Contact  string            `json:"contact" jsonschema:"format=email"`
+	Weights  map[string]int    `json:"weights" jsonschema:"description=Weight of each parcel"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Priority int               `json:"priority" jsonschema:"enum=1,enum=2"`
+	Parent   *Shipment         `json:"parent,omitempty"`
+	Internal string            `json:"-"`
+}
+
+func TestGoogleAISchemaTags(t *testing.T) {
+	schema, err := instructor.GoogleAISchemaOf(Shipment{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := schema.Properties["status"]
+	if status.Format != "enum" || !slices.Equal(status.Enum, []string{"pending", "shipped"}) {
+		t.Errorf("status = %+v", status)
+	}
+
+	if carrier := schema.Properties["carrier"]; carrier.Description != "Name of the carrier" {
+		t.Errorf("carrier = %+v", carrier)
+	}
+
+	// genai only takes enums of strings and a few formats, the rest are hinted
+	contact := schema.Properties["contact"]
+	if contact.Format != "" || !strings.Contains(contact.Description, "Format: email") {
+		t.Errorf("contact = %+v", contact)
+	}
+	priority := schema.Properties["priority"]
+	if priority.Type != genai.TypeInteger || len(priority.Enum) != 0 || !strings.Contains(priority.Description, "One of: 1, 2") {
+		t.Errorf("priority = %+v", priority)
+	}
+
+	if _, ok := schema.Properties["Internal"]; ok {
+		t.Errorf("field tagged json:\"-\" in schema")
+	}
+
+	for _, name := range []string{"status", "contact", "weights", "priority", "created_by", "created_at"} {
+		if !slices.Contains(schema.Required, name) {
+			t.Errorf("%s not required: %v", name, schema.Required)
+		}
+	}
+	for _, name := range []string{"carrier", "labels", "parent"} {
+		if slices.Contains(schema.Required, name) {
+			t.Errorf("%s required: %v", name, schema.Required)
+		}
+	}
+}
+
+func TestGoogleAISchemaEmbedded(t *testing.T) {
+	schema, err := instructor.GoogleAISchemaOf(Shipment{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := schema.Properties["Audit"]; ok {
+		t.Errorf("embedded struct not inlined: %v", schema.Properties)
+	}
+	if createdBy := schema.Properties["created_by"]; createdBy == nil || createdBy.Description != "Who created the record" {
+		t.Errorf("created_by = %+v", createdBy)
+	}
+	if createdAt := schema.Properties["created_at"]; createdAt == nil || createdAt.Type != genai.TypeString || createdAt.Format != "date-time" {
+		t.Errorf("created_at = %+v", createdAt)
+	}
+}
+
+func TestGoogleAISchemaMaps(t *testing.T) {
+	schema, err := instructor.GoogleAISchemaOf(Shipment{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weights := schema.Properties["weights"]
+	if weights.Type != genai.TypeObject || weights.Description != "Weight of each parcel (Object mapping keys to integer values)" {
+		t.Errorf("weights = %+v", weights)
+	}
+	if labels := schema.Properties["labels"]; labels.Description != "Object mapping keys to string values" {
+		t.Errorf("labels = %+v", labels)
+	}
+}
+
+func TestGoogleAISchemaRecursive(t *testing.T) {
+	schema, err := instructor.GoogleAISchemaOf(Shipment{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unrolled to a depth of three, the recursive field left out below that
+	depth := 1
+	for parent := schema.Properties["parent"]; parent != nil; parent = parent.Properties["parent"] {
+		if !parent.Nullable || parent.Properties["status"] == nil {
+			t.Errorf("parent at depth %d = %+v", depth, parent)
+		}
+		depth++
+	}
+	if depth != 3 {
+		t.Errorf("unrolled to a depth of %d, want 3", depth)
+	}
+}
@@ -0,0 +1,515 @@
+package instructor_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/invopop/jsonschema"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type grammarAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+	Zip    *int   `json:"zip,omitempty"`
+}
+
+type grammarCategory struct {
+	Name     string            `json:"name"`
+	Children []grammarCategory `json:"children,omitempty"`
+}
+
+type grammarOrder struct {
+	ID        int64             `json:"id"`
+	Status    string            `json:"status"              jsonschema:"enum=open,enum=shipped,enum=cancelled"`
+	Total     float64           `json:"total"`
+	Paid      bool              `json:"paid"`
+	Note      string            `json:"note,omitempty"`
+	Quantity  uint              `json:"quantity,omitempty"  jsonschema:"minimum=0"`
+	Address   *grammarAddress   `json:"address,omitempty"`
+	Items     []grammarAddress  `json:"items"`
+	Tags      map[string]int    `json:"tags,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	Signature []byte            `json:"signature,omitempty"`
+	Category  grammarCategory   `json:"category"`
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+func TestGrammarSamplesUnmarshal(t *testing.T) {
+	types := []any{Person{}, grammarOrder{}, []grammarAddress{}}
+
+	for _, v := range types {
+		typ := reflect.TypeOf(v)
+
+		t.Run(typ.String(), func(t *testing.T) {
+			schema, err := instructor.NewSchema(typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			grammar, err := schema.Grammar()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sampler, err := newGBNFSampler(grammar)
+			if err != nil {
+				t.Fatalf("parsing grammar: %v\n%s", err, grammar)
+			}
+
+			rng := rand.New(rand.NewSource(1))
+			for n := 0; n < 300; n++ {
+				sample := sampler.sample(rng)
+
+				target := reflect.New(typ).Interface()
+				if typ.Kind() == reflect.Slice {
+					// the response is wrapped in an envelope object
+					target = &struct {
+						Result any `json:"result"`
+					}{Result: target}
+				}
+
+				decoder := json.NewDecoder(strings.NewReader(sample))
+				decoder.DisallowUnknownFields()
+				if err := decoder.Decode(target); err != nil {
+					t.Fatalf("sample doesn't unmarshal: %v\n%s\n\ngrammar:\n%s", err, sample, grammar)
+				}
+			}
+		})
+	}
+}
+
+func TestGrammarManyOptionalProperties(t *testing.T) {
+	// 32 omitempty fields, as many subsets as a grammar would have to spell out
+	fields := make([]reflect.StructField, 32)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"f%d,omitempty"`, i)),
+		}
+	}
+	typ := reflect.StructOf(fields)
+
+	start := time.Now()
+	grammar, err := instructor.ToGBNF((&jsonschema.Reflector{}).ReflectFromType(typ))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("generating the grammar took %s", elapsed)
+	}
+
+	// one rest rule per property but the last
+	if rests := strings.Count(grammar, "-rest ::="); rests != len(fields)-1 {
+		t.Errorf("%d rest rules, want %d", rests, len(fields)-1)
+	}
+
+	sampler, err := newGBNFSampler(grammar)
+	if err != nil {
+		t.Fatalf("parsing grammar: %v\n%s", err, grammar)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 100; n++ {
+		sample := sampler.sample(rng)
+
+		decoder := json.NewDecoder(strings.NewReader(sample))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(reflect.New(typ).Interface()); err != nil {
+			t.Fatalf("sample doesn't unmarshal: %v\n%s", err, sample)
+		}
+	}
+}
+
+func TestGrammarMode(t *testing.T) {
+	config, requests := compatibleServer(t,
+		`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"name\":\"Joe\",\"age\":42}"}}]}`,
+	)
+
+	client := instructor.FromOpenAICompatible(config, instructor.ProfileLlamaCpp, instructor.WithMode(instructor.ModeGrammar))
+
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: "llama-3.1-8b",
+	}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+
+	grammar, _ := (*requests)[0]["grammar"].(string)
+	if !strings.HasPrefix(grammar, "root ::= Person\n") {
+		t.Errorf("grammar = %q", grammar)
+	}
+
+	if err := instructor.FromOpenAI(openai.NewClientWithConfig(config), instructor.WithMode(instructor.ModeGrammar)).Err(); err == nil {
+		t.Errorf("grammar mode accepted by OpenAI")
+	}
+}
+
+// gbnfSampler generates random strings of a GBNF grammar, as written by
+// instructor.ToGBNF.
+type gbnfSampler struct {
+	rules map[string]*gbnfNode
+	cost  map[string]int
+}
+
+type gbnfNode struct {
+	kind     string // "alt", "seq", "lit", "class", "ref", "repeat"
+	children []*gbnfNode
+	text     string // literal text or rule name
+	negated  bool
+	ranges   [][2]rune
+	min      int
+	max      int // -1 for unbounded
+}
+
+func newGBNFSampler(grammar string) (*gbnfSampler, error) {
+	s := &gbnfSampler{rules: map[string]*gbnfNode{}}
+
+	for _, line := range strings.Split(strings.TrimSpace(grammar), "\n") {
+		name, body, ok := strings.Cut(line, " ::= ")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule %q", line)
+		}
+
+		p := &gbnfParser{input: []rune(body)}
+		node, err := p.alternatives()
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		if p.skipSpace(); p.pos != len(p.input) {
+			return nil, fmt.Errorf("rule %s: unexpected %q", name, string(p.input[p.pos:]))
+		}
+		s.rules[name] = node
+	}
+
+	for name, node := range s.rules {
+		if err := s.checkRefs(node); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+
+	// the cost of a rule is how deep its shortest expansion goes
+	s.cost = map[string]int{}
+	for name := range s.rules {
+		s.cost[name] = 1 << 20
+	}
+	for changed := true; changed; {
+		changed = false
+		for name, node := range s.rules {
+			if c := s.nodeCost(node); c < s.cost[name] {
+				s.cost[name] = c
+				changed = true
+			}
+		}
+	}
+
+	return s, nil
+}
+
+func (s *gbnfSampler) checkRefs(node *gbnfNode) error {
+	if node.kind == "ref" {
+		if _, ok := s.rules[node.text]; !ok {
+			return fmt.Errorf("undefined rule %s", node.text)
+		}
+	}
+	for _, child := range node.children {
+		if err := s.checkRefs(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *gbnfSampler) nodeCost(node *gbnfNode) int {
+	switch node.kind {
+	case "ref":
+		return s.cost[node.text] + 1
+	case "alt":
+		best := 1 << 20
+		for _, child := range node.children {
+			best = min(best, s.nodeCost(child))
+		}
+		return best
+	case "seq":
+		worst := 0
+		for _, child := range node.children {
+			worst = max(worst, s.nodeCost(child))
+		}
+		return worst
+	case "repeat":
+		if node.min == 0 {
+			return 0
+		}
+		return s.nodeCost(node.children[0])
+	}
+	return 0
+}
+
+func (s *gbnfSampler) sample(rng *rand.Rand) string {
+	var b strings.Builder
+	s.generate(&b, s.rules["root"], rng, 0)
+	return b.String()
+}
+
+// generate writes a random expansion of node, taking the shortest ones once
+// the expansion gets deep.
+func (s *gbnfSampler) generate(b *strings.Builder, node *gbnfNode, rng *rand.Rand, depth int) {
+	deep := depth > 12
+
+	switch node.kind {
+	case "lit":
+		b.WriteString(node.text)
+
+	case "class":
+		b.WriteRune(node.sampleRune(rng))
+
+	case "ref":
+		s.generate(b, s.rules[node.text], rng, depth+1)
+
+	case "seq":
+		for _, child := range node.children {
+			s.generate(b, child, rng, depth)
+		}
+
+	case "alt":
+		choice := node.children[rng.Intn(len(node.children))]
+		if deep {
+			for _, child := range node.children {
+				if s.nodeCost(child) < s.nodeCost(choice) {
+					choice = child
+				}
+			}
+		}
+		s.generate(b, choice, rng, depth)
+
+	case "repeat":
+		n := node.min
+		limit := node.max
+		if limit < 0 {
+			limit = node.min + 4
+		}
+		if !deep {
+			n += rng.Intn(limit - node.min + 1)
+		}
+		for i := 0; i < n; i++ {
+			s.generate(b, node.children[0], rng, depth)
+		}
+	}
+}
+
+func (n *gbnfNode) sampleRune(rng *rand.Rand) rune {
+	if !n.negated {
+		r := n.ranges[rng.Intn(len(n.ranges))]
+		return r[0] + rune(rng.Intn(int(r[1]-r[0]+1)))
+	}
+
+	// printable ASCII and a few others outside the class
+	candidates := []rune("aZ09 _-.,:;!?'é€")
+	for {
+		c := candidates[rng.Intn(len(candidates))]
+		if rng.Intn(2) == 0 {
+			c = rune(0x20 + rng.Intn(0x5F))
+		}
+		excluded := false
+		for _, r := range n.ranges {
+			if c >= r[0] && c <= r[1] {
+				excluded = true
+			}
+		}
+		if !excluded {
+			return c
+		}
+	}
+}
+
+type gbnfParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *gbnfParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *gbnfParser) peek() rune {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *gbnfParser) alternatives() (*gbnfNode, error) {
+	alt := &gbnfNode{kind: "alt"}
+	for {
+		seq, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		alt.children = append(alt.children, seq)
+
+		if p.peek() != '|' {
+			return alt, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *gbnfParser) sequence() (*gbnfNode, error) {
+	seq := &gbnfNode{kind: "seq"}
+	for {
+		c := p.peek()
+		if c == 0 || c == '|' || c == ')' {
+			return seq, nil
+		}
+
+		item, err := p.item()
+		if err != nil {
+			return nil, err
+		}
+
+		switch p.peekRaw() {
+		case '?':
+			item = &gbnfNode{kind: "repeat", children: []*gbnfNode{item}, min: 0, max: 1}
+			p.pos++
+		case '*':
+			item = &gbnfNode{kind: "repeat", children: []*gbnfNode{item}, min: 0, max: -1}
+			p.pos++
+		case '+':
+			item = &gbnfNode{kind: "repeat", children: []*gbnfNode{item}, min: 1, max: -1}
+			p.pos++
+		}
+
+		seq.children = append(seq.children, item)
+	}
+}
+
+// peekRaw returns the next rune without skipping spaces, as postfix operators
+// follow their item directly.
+func (p *gbnfParser) peekRaw() rune {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *gbnfParser) item() (*gbnfNode, error) {
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		node, err := p.alternatives()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ) at %d", p.pos)
+		}
+		p.pos++
+		return node, nil
+
+	case c == '"':
+		p.pos++
+		var text strings.Builder
+		for p.pos < len(p.input) && p.input[p.pos] != '"' {
+			r, err := p.char()
+			if err != nil {
+				return nil, err
+			}
+			text.WriteRune(r)
+		}
+		if p.pos >= len(p.input) {
+			return nil, fmt.Errorf("unterminated literal")
+		}
+		p.pos++
+		return &gbnfNode{kind: "lit", text: text.String()}, nil
+
+	case c == '[':
+		p.pos++
+		node := &gbnfNode{kind: "class"}
+		if p.peekRaw() == '^' {
+			node.negated = true
+			p.pos++
+		}
+		for p.pos < len(p.input) && p.input[p.pos] != ']' {
+			lo, err := p.char()
+			if err != nil {
+				return nil, err
+			}
+			hi := lo
+			if p.peekRaw() == '-' && p.pos+1 < len(p.input) && p.input[p.pos+1] != ']' {
+				p.pos++
+				if hi, err = p.char(); err != nil {
+					return nil, err
+				}
+			}
+			node.ranges = append(node.ranges, [2]rune{lo, hi})
+		}
+		if p.pos >= len(p.input) {
+			return nil, fmt.Errorf("unterminated class")
+		}
+		p.pos++
+		return node, nil
+
+	case c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos < len(p.input) {
+			r := p.input[p.pos]
+			if r != '-' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+				break
+			}
+			p.pos++
+		}
+		return &gbnfNode{kind: "ref", text: string(p.input[start:p.pos])}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected %q at %d", p.peekRaw(), p.pos)
+}
+
+// char reads a possibly escaped character of a literal or class.
+func (p *gbnfParser) char() (rune, error) {
+	r := p.input[p.pos]
+	p.pos++
+	if r != '\\' {
+		return r, nil
+	}
+
+	if p.pos >= len(p.input) {
+		return 0, fmt.Errorf("dangling escape")
+	}
+	e := p.input[p.pos]
+	p.pos++
+
+	switch e {
+	case 'n':
+		return '\n', nil
+	case 't':
+		return '\t', nil
+	case 'r':
+		return '\r', nil
+	case 'x':
+		if p.pos+2 > len(p.input) {
+			return 0, fmt.Errorf("short \\x escape")
+		}
+		v, err := strconv.ParseUint(string(p.input[p.pos:p.pos+2]), 16, 8)
+		p.pos += 2
+		return rune(v), err
+	}
+	return e, nil
+}
@@ -0,0 +1,131 @@
+package instructor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+// labels returns the labels of tickets.
+func labels(tickets []Ticket) []Label {
+	var labels []Label
+	for _, ticket := range tickets {
+		labels = append(labels, ticket.Label)
+	}
+	return labels
+}
+
+func TestCreateIterable(t *testing.T) {
+	srv, _ := openaiAPI.start(t,
+		contentCompletion(`"{\"items\":[{\"name\":\"Joe\",\"age\":42},{\"name\":\"Ann\",\"age\":37}]}"`),
+		// models sometimes leave out the items object
+		contentCompletion(`"[{\"name\":\"Bob\"}]"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSON))
+
+	people, _, err := instructor.CreateIterable[Person](context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 2 || people[0] != (Person{Name: "Joe", Age: 42}) || people[1] != (Person{Name: "Ann", Age: 37}) {
+		t.Errorf("people = %+v", people)
+	}
+
+	people, _, err = instructor.CreateIterable[Person](context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 1 || people[0].Name != "Bob" {
+		t.Errorf("people = %+v", people)
+	}
+}
+
+func TestCreateIterableReasksInvalidItems(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		contentCompletion(`"{\"items\":[{\"label\":\"billing\"},{\"label\":\"refund\"}]}"`),
+		contentCompletion(`"{\"items\":[{\"label\":\"tech_issue\"}]}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(1),
+	)
+
+	tickets, _, err := instructor.CreateIterable[Ticket](context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labels(tickets); len(got) != 2 || got[0] != "billing" || got[1] != "tech_issue" {
+		t.Errorf("labels = %v", got)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(*requests))
+	}
+
+	// only the invalid item is asked for again
+	messages := (*requests)[1]["messages"].([]any)
+	feedback := messages[len(messages)-1].(map[string]any)["content"].(string)
+	if !strings.Contains(feedback, `item 2 {"label":"refund"}`) || strings.Contains(feedback, "item 1") {
+		t.Errorf("feedback = %q", feedback)
+	}
+	if !strings.Contains(feedback, "corrected versions of only these items") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestCreateIterableOutOfRetries(t *testing.T) {
+	srv, _ := openaiAPI.start(t,
+		contentCompletion(`"{\"items\":[{\"label\":\"billing\"},{\"label\":\"refund\"}]}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(0),
+	)
+
+	// the valid items are returned with the error
+	tickets, _, err := instructor.CreateIterable[Ticket](context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+	var enumErr *instructor.EnumError
+	if !errors.As(err, &enumErr) || enumErr.Path != "$.label" {
+		t.Fatalf("err = %v, want the EnumError of the invalid item", err)
+	}
+	if !strings.HasPrefix(err.Error(), "hit max retry attempts") {
+		t.Errorf("err = %v", err)
+	}
+	if got := labels(tickets); len(got) != 1 || got[0] != "billing" {
+		t.Errorf("labels = %v", got)
+	}
+}
+
+func TestCreateIterableToolCalls(t *testing.T) {
+	// the items of parallel calls are merged
+	srv, requests := openaiAPI.start(t, `{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[`+
+		`{"id":"call_1","type":"function","function":{"name":"PersonList","arguments":"{\"items\":[{\"name\":\"Joe\",\"age\":42}]}"}},`+
+		`{"id":"call_2","type":"function","function":{"name":"PersonList","arguments":"{\"items\":[{\"name\":\"Ann\",\"age\":37}]}"}}`+
+		`]}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	people, resp, err := instructor.CreateIterable[Person](context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 2 || people[0].Name != "Joe" || people[1].Name != "Ann" {
+		t.Errorf("people = %+v", people)
+	}
+	if usage := resp.(*openai.ChatCompletionResponse).Usage; usage.TotalTokens != 15 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	tools := (*requests)[0]["tools"].([]any)
+	if name := tools[0].(map[string]any)["function"].(map[string]any)["name"]; len(tools) != 1 || name != "PersonList" {
+		t.Errorf("tools = %v", tools)
+	}
+}
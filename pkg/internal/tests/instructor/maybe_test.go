@@ -0,0 +1,84 @@
+package instructor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+func TestMaybeFound(t *testing.T) {
+	srv, requests := openaiAPI.start(t, toolCallCompletion("MaybePerson", `"{\"result\":{\"name\":\"Joe\",\"age\":42},\"error\":false}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	var maybe instructor.Maybe[Person]
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &maybe); err != nil {
+		t.Fatal(err)
+	}
+
+	person, found, reason := maybe.Unwrap()
+	if !found || person.Name != "Joe" || person.Age != 42 || reason != "" {
+		t.Errorf("unwrapped %+v, %v, %q", person, found, reason)
+	}
+
+	// named without the package path or brackets of the type argument
+	tools := (*requests)[0]["tools"].([]any)
+	if name := tools[0].(map[string]any)["function"].(map[string]any)["name"]; name != "MaybePerson" {
+		t.Errorf("tool name = %v", name)
+	}
+}
+
+func TestMaybeNotFound(t *testing.T) {
+	srv, _ := openaiAPI.start(t, contentCompletion(`"{\"error\":true,\"message\":\"nobody is mentioned\"}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSON))
+
+	var maybe instructor.Maybe[Person]
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &maybe); err != nil {
+		t.Fatal(err)
+	}
+
+	person, found, reason := maybe.Unwrap()
+	if found || person != (Person{}) || reason != "nobody is mentioned" {
+		t.Errorf("unwrapped %+v, %v, %q", person, found, reason)
+	}
+}
+
+func TestMaybeChecksPresentResult(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		contentCompletion(`"{\"error\":true,\"message\":\"not a ticket\"}"`),
+		contentCompletion(`"{\"result\":{\"label\":\"refund\"},\"error\":false}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(0),
+	)
+
+	// a missing result has no label to check
+	var missing instructor.Maybe[Ticket]
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &missing); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := missing.Unwrap(); found {
+		t.Errorf("found %+v", missing)
+	}
+
+	var present instructor.Maybe[Ticket]
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &present)
+
+	var enumErr *instructor.EnumError
+	if !errors.As(err, &enumErr) {
+		t.Fatalf("err = %v, want an EnumError", err)
+	}
+	if enumErr.Value != Label("refund") {
+		t.Errorf("value = %v", enumErr.Value)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("sent %d requests, want 2", len(*requests))
+	}
+}
@@ -0,0 +1,159 @@
+package instructor_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+	"github.com/binarycraft007/instructor-go/pkg/instructor/mistral"
+)
+
+// mistralAPI is the Mistral chat completions API, authenticated with "key".
+var mistralAPI = cannedAPI{
+	path:          "/chat/completions",
+	authorization: "Bearer key",
+	status:        http.StatusUnprocessableEntity,
+	exhausted:     `{"message":"no more responses"}`,
+}
+
+func TestMistralToolCall(t *testing.T) {
+	srv, requests := mistralAPI.start(t,
+		`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","content":"","tool_calls":[{"id":"abc123def","function":{"name":"Person","arguments":"{\"name\":\"Joe\",\"age\":42}"}}]}}],"usage":{"prompt_tokens":30,"completion_tokens":10,"total_tokens":40}}`,
+	)
+
+	client := instructor.FromMistral(
+		mistral.NewClient("key", mistral.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeToolCall),
+	)
+
+	var person Person
+	resp, err := client.CreateChatCompletion(context.Background(), mistral.ChatCompletionRequest{
+		Model:    "mistral-large-latest",
+		Messages: []mistral.Message{{Role: mistral.RoleUser, Content: "Joe is 42"}},
+	}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+	if resp.Usage.TotalTokens != 40 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	request := (*requests)[0]
+	if request["tool_choice"] != "any" {
+		t.Errorf("tool_choice = %v, want any", request["tool_choice"])
+	}
+	if tools, _ := request["tools"].([]any); len(tools) != 1 {
+		t.Errorf("got %d tools, want 1", len(tools))
+	}
+}
+
+func TestMistralJSON(t *testing.T) {
+	srv, requests := mistralAPI.start(t,
+		`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"name\":\"Ann\"}"}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+		`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"name\":\"Ann\",\"age\":30}"}}],"usage":{"prompt_tokens":20,"completion_tokens":5,"total_tokens":25}}`,
+	)
+
+	client := instructor.FromMistral(
+		mistral.NewClient("key", mistral.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(1),
+		instructor.WithValidation(),
+	)
+
+	var person struct {
+		Name string `json:"name" validate:"required"`
+		Age  int    `json:"age"  validate:"required"`
+	}
+	resp, err := client.CreateChatCompletion(context.Background(), mistral.ChatCompletionRequest{
+		Model: "mistral-small-latest",
+	}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Age != 30 {
+		t.Errorf("got %+v", person)
+	}
+	if resp.Usage.TotalTokens != 40 {
+		t.Errorf("usage not summed: %+v", resp.Usage)
+	}
+
+	format, _ := (*requests)[0]["response_format"].(map[string]any)
+	if format["type"] != "json_object" {
+		t.Errorf("response_format = %v", (*requests)[0]["response_format"])
+	}
+	// the retry answers the invalid response, with the schema prompt once
+	messages, _ := (*requests)[1]["messages"].([]any)
+	var prompts int
+	for _, message := range messages {
+		if message.(map[string]any)["role"] == "system" {
+			prompts++
+		}
+	}
+	if prompts != 1 {
+		t.Errorf("retry has %d schema prompts, want 1", prompts)
+	}
+	if feedback, _ := messages[len(messages)-1].(map[string]any)["content"].(string); !strings.Contains(feedback, "'required' tag") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestMistralStream(t *testing.T) {
+	chunks := []string{`{"items": [`, `{"name":"Ann","age":30},`, `{"name":"Bob",`, `"age":40}`, `]}`}
+
+	var body strings.Builder
+	for _, chunk := range chunks {
+		b, _ := json.Marshal(chunk)
+		fmt.Fprintf(&body, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":%s}}]}\n\n", b)
+	}
+	body.WriteString("data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"\"},\"finish_reason\":\"stop\"}],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":12,\"total_tokens\":17}}\n\n")
+	body.WriteString("data: [DONE]\n\n")
+
+	srv, requests := mistralAPI.start(t, body.String())
+
+	client := instructor.FromMistral(
+		mistral.NewClient("key", mistral.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeJSON),
+	)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), mistral.ChatCompletionRequest{
+		Model: "mistral-small-latest",
+	}, *new(Person))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for item := range stream {
+		names = append(names, item.(*Person).Name)
+	}
+
+	if fmt.Sprint(names) != "[Ann Bob]" {
+		t.Errorf("got %v", names)
+	}
+	if (*requests)[0]["stream"] != true {
+		t.Errorf("stream not requested: %v", (*requests)[0]["stream"])
+	}
+}
+
+func TestMistralAPIError(t *testing.T) {
+	srv, _ := mistralAPI.start(t)
+
+	client := instructor.FromMistral(mistral.NewClient("key", mistral.WithBaseURL(srv.URL)), instructor.WithMode(instructor.ModeJSON))
+
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), mistral.ChatCompletionRequest{Model: "mistral-small-latest"}, &person)
+
+	apiErr, ok := err.(*mistral.APIError)
+	if !ok || apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Message != "no more responses" {
+		t.Fatalf("got %v, want the API error", err)
+	}
+}
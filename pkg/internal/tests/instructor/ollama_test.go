@@ -0,0 +1,158 @@
+package instructor_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+	"github.com/binarycraft007/instructor-go/pkg/instructor/ollama"
+)
+
+// ollamaAPI is the Ollama chat API.
+var ollamaAPI = cannedAPI{
+	path:      "/api/chat",
+	status:    http.StatusInternalServerError,
+	exhausted: `{"error":"no more responses"}`,
+}
+
+func TestOllamaChatJSONSchema(t *testing.T) {
+	srv, requests := ollamaAPI.start(t,
+		`{"model":"llama3.1","message":{"role":"assistant","content":"{\"name\":\"Joe\",\"age\":\"old\"}"},"done":true,"prompt_eval_count":10,"eval_count":5}`,
+		`{"model":"llama3.1","message":{"role":"assistant","content":"{\"name\":\"Joe\",\"age\":42}"},"done":true,"done_reason":"stop","prompt_eval_count":20,"eval_count":6}`,
+	)
+
+	client := instructor.FromOllama(
+		ollama.NewClient(ollama.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeJSONSchema),
+		instructor.WithMaxRetries(1),
+	)
+
+	request := &ollama.ChatRequest{
+		Model:     "llama3.1",
+		Messages:  []ollama.Message{{Role: ollama.RoleUser, Content: "Joe is 42"}},
+		Options:   map[string]any{"num_ctx": 8192},
+		KeepAlive: "10m",
+	}
+
+	var person Person
+	resp, err := client.Chat(context.Background(), request, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+	if resp.PromptEvalCount != 30 || resp.EvalCount != 11 {
+		t.Errorf("usage not summed: %d prompt, %d eval", resp.PromptEvalCount, resp.EvalCount)
+	}
+	if len(request.Messages) != 1 {
+		t.Errorf("caller's request was modified: %d messages", len(request.Messages))
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(*requests))
+	}
+	first := (*requests)[0]
+	if _, ok := first["format"].(map[string]any); !ok {
+		t.Errorf("format is not a schema: %v", first["format"])
+	}
+	if first["stream"] != false || first["keep_alive"] != "10m" {
+		t.Errorf("unexpected request %v", first)
+	}
+	if options, _ := first["options"].(map[string]any); options["num_ctx"] != float64(8192) {
+		t.Errorf("options not passed: %v", first["options"])
+	}
+	if messages, _ := (*requests)[1]["messages"].([]any); len(messages) != 2 {
+		t.Errorf("retry has %d messages, want the schema prompt once and the user message", len(messages))
+	}
+}
+
+func TestOllamaChatJSON(t *testing.T) {
+	srv, requests := ollamaAPI.start(t,
+		`{"model":"llama3.1","message":{"role":"assistant","content":"{\"name\":\"Ann\"}"},"done":true}`,
+	)
+
+	client := instructor.FromOllama(
+		ollama.NewClient(ollama.WithBaseURL(srv.URL)),
+		instructor.WithMode(instructor.ModeJSON),
+	)
+
+	var person Person
+	if _, err := client.Chat(context.Background(), &ollama.ChatRequest{Model: "llama3.1"}, &person); err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Ann" {
+		t.Errorf("got %+v", person)
+	}
+	if format := (*requests)[0]["format"]; format != "json" {
+		t.Errorf("format = %v, want json", format)
+	}
+}
+
+func TestOllamaChatTruncated(t *testing.T) {
+	srv, _ := ollamaAPI.start(t,
+		`{"model":"llama3.1","message":{"role":"assistant","content":"{\"name\":"},"done":true,"done_reason":"length"}`,
+	)
+
+	client := instructor.FromOllama(ollama.NewClient(ollama.WithBaseURL(srv.URL)))
+
+	var person Person
+	_, err := client.Chat(context.Background(), &ollama.ChatRequest{Model: "llama3.1"}, &person)
+
+	var truncated *instructor.TruncationError
+	if !errors.As(err, &truncated) {
+		t.Fatalf("got %v, want a truncation error", err)
+	}
+}
+
+func TestOllamaAPIError(t *testing.T) {
+	srv, _ := ollamaAPI.start(t)
+
+	client := instructor.FromOllama(ollama.NewClient(ollama.WithBaseURL(srv.URL)))
+
+	var person Person
+	_, err := client.Chat(context.Background(), &ollama.ChatRequest{Model: "llama3.1"}, &person)
+
+	var apiErr *ollama.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError || apiErr.Message != "no more responses" {
+		t.Fatalf("got %v, want the API error", err)
+	}
+}
+
+func TestOllamaChatStream(t *testing.T) {
+	chunks := []string{`{"items": [`, `{"name":"Ann","age":30},`, `{"name":"Bob",`, `"age":40}`, `]}`}
+
+	body := ""
+	for _, chunk := range chunks {
+		b, _ := json.Marshal(chunk)
+		body += fmt.Sprintf(`{"model":"llama3.1","message":{"role":"assistant","content":%s},"done":false}`+"\n", b)
+	}
+	body += `{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"eval_count":12}` + "\n"
+
+	srv, requests := ollamaAPI.start(t, body)
+
+	client := instructor.FromOllama(ollama.NewClient(ollama.WithBaseURL(srv.URL)))
+
+	stream, err := client.ChatStream(context.Background(), &ollama.ChatRequest{Model: "llama3.1"}, *new(Person))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for item := range stream {
+		names = append(names, item.(*Person).Name)
+	}
+
+	if fmt.Sprint(names) != "[Ann Bob]" {
+		t.Errorf("got %v", names)
+	}
+	if (*requests)[0]["stream"] != true {
+		t.Errorf("stream not requested: %v", (*requests)[0]["stream"])
+	}
+}
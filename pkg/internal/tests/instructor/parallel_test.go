@@ -0,0 +1,104 @@
+package instructor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Search struct {
+	Query string `json:"query"`
+}
+
+type Tags []string
+
+func TestCreateParallel(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[`+
+			`{"id":"call_1","type":"function","function":{"name":"Search","arguments":"{\"query\":\"weather in Paris\"}"}},`+
+			`{"id":"call_2","type":"function","function":{"name":"Tags","arguments":"{\"result\":[\"travel\",\"weather\"]}"}}`+
+			`]}}],"usage":{"prompt_tokens":30,"completion_tokens":20,"total_tokens":50}}`,
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	results, resp, err := instructor.CreateParallel(context.Background(), client, openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "What's the weather in Paris? Tag the question."}},
+	}, Search{}, Tags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if search, ok := results[0].(*Search); !ok || search.Query != "weather in Paris" {
+		t.Errorf("results[0] = %#v", results[0])
+	}
+	if tags, ok := results[1].(*Tags); !ok || len(*tags) != 2 || (*tags)[1] != "weather" {
+		t.Errorf("results[1] = %#v", results[1])
+	}
+	if usage := resp.(*openai.ChatCompletionResponse).Usage; usage.TotalTokens != 50 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	if tools, _ := (*requests)[0]["tools"].([]any); len(tools) != 2 {
+		t.Errorf("got %d tools, want 2", len(tools))
+	}
+}
+
+func TestCreateParallelReask(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"Lookup","arguments":"{}"}}]}}]}`,
+		`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"Search","arguments":"{\"query\":\"Paris\"}"}}]}}]}`,
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	results, _, err := instructor.CreateParallel(context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o}, Search{}, Tags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if len(*requests) != 2 {
+		t.Errorf("sent %d requests, want 2", len(*requests))
+	}
+}
+
+func TestCreateParallelOutOfRetries(t *testing.T) {
+	srv, _ := openaiAPI.start(t,
+		`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"Ticket","arguments":"{\"label\":\"refund\"}"}}]}}]}`,
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeToolCall),
+		instructor.WithMaxRetries(0),
+	)
+
+	_, _, err := instructor.CreateParallel(context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o}, Search{}, Ticket{})
+
+	var enumErr *instructor.EnumError
+	if !errors.As(err, &enumErr) || enumErr.Path != "$.label" {
+		t.Fatalf("err = %v, want the EnumError of the last tool call", err)
+	}
+	if !strings.HasPrefix(err.Error(), "hit max retry attempts") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCreateParallelMode(t *testing.T) {
+	client := instructor.FromOpenAI(openai.NewClient("key"), instructor.WithMode(instructor.ModeJSON))
+
+	if _, _, err := instructor.CreateParallel(context.Background(), client, openai.ChatCompletionRequest{Model: openai.GPT4o}, Search{}); err == nil {
+		t.Error("no error for ModeJSON")
+	}
+}
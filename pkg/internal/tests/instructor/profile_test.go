@@ -0,0 +1,107 @@
+package instructor_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+// compatibleServer answers chat completions with body, recording the requests
+// it received.
+func compatibleServer(t *testing.T, body string) (openai.ClientConfig, *[]map[string]any) {
+	t.Helper()
+
+	var requests []map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	config := openai.DefaultConfig("key")
+	config.BaseURL = srv.URL + "/v1"
+
+	return config, &requests
+}
+
+func TestProfileExtraBody(t *testing.T) {
+	config, requests := compatibleServer(t,
+		`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"name\":\"Joe\",\"age\":42}"}}]}`,
+	)
+
+	client := instructor.FromOpenAICompatible(config, instructor.ProfileVLLM, instructor.WithMode(instructor.ModeJSON))
+
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: "meta-llama/Meta-Llama-3.1-8B-Instruct",
+	}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+
+	guided, _ := (*requests)[0]["guided_json"].(map[string]any)
+	if guided["$ref"] != "#/$defs/Person" {
+		t.Errorf("guided_json = %v", (*requests)[0]["guided_json"])
+	}
+}
+
+func TestProfileUnsupportedFields(t *testing.T) {
+	config, requests := compatibleServer(t,
+		`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"Person","arguments":"{\"name\":\"Joe\",\"age\":42}"}}]}}]}`,
+	)
+
+	client := instructor.FromOpenAICompatible(config, instructor.ProfileGroq, instructor.WithMode(instructor.ModeToolCall))
+
+	var person Person
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:     "llama-3.1-70b-versatile",
+		LogProbs:  true,
+		LogitBias: map[string]int{"1": 10},
+	}, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := (*requests)[0]
+	if _, ok := request["logprobs"]; ok {
+		t.Errorf("logprobs sent to Groq")
+	}
+	if _, ok := request["logit_bias"]; ok {
+		t.Errorf("logit_bias sent to Groq")
+	}
+	if request["model"] != "llama-3.1-70b-versatile" {
+		t.Errorf("model = %v", request["model"])
+	}
+}
+
+func TestProfileModeNotSupported(t *testing.T) {
+	config, _ := compatibleServer(t, `{}`)
+
+	err := instructor.FromOpenAICompatible(config, instructor.ProfileGroq, instructor.WithMode(instructor.ModeStructuredOutputs)).Err()
+
+	if err == nil || !strings.Contains(err.Error(), "not supported by Groq: no json_schema response format") {
+		t.Fatalf("got %v, want the unsupported mode error", err)
+	}
+	if instructor.ProfileGroq.SupportsMode(instructor.ModeStructuredOutputs) {
+		t.Errorf("Groq reported to support structured outputs")
+	}
+}
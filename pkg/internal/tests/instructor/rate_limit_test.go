@@ -0,0 +1,151 @@
+package instructor_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+// shortDeadline returns a context that can't wait for a slot in the next
+// minute.
+func shortDeadline(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestRateLimiterRequests(t *testing.T) {
+	limiter := instructor.NewRateLimiter(2, 0)
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(context.Background(), 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := limiter.Wait(shortDeadline(t), 1); !errors.Is(err, instructor.ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestRateLimiterTokens(t *testing.T) {
+	limiter := instructor.NewRateLimiter(0, 100)
+
+	if err := limiter.Wait(context.Background(), 60); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.Wait(shortDeadline(t), 40); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.Wait(shortDeadline(t), 1); !errors.Is(err, instructor.ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+
+	// without a deadline, waiting ends with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRateLimiterOversizedRequest(t *testing.T) {
+	limiter := instructor.NewRateLimiter(0, 100)
+
+	// a request larger than the limit is let through once the window is empty
+	if err := limiter.Wait(shortDeadline(t), 500); err != nil {
+		t.Fatal(err)
+	}
+	if err := limiter.Wait(shortDeadline(t), 1); !errors.Is(err, instructor.ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestRateLimiterRecordsUsage(t *testing.T) {
+	srv, _ := openaiAPI.start(t, personCompletion)
+
+	limiter := instructor.NewRateLimiter(0, 100)
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithRateLimiter(limiter),
+	)
+
+	var person Person
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Joe is 42"}},
+	}, &person); err != nil {
+		t.Fatal(err)
+	}
+
+	// the estimate is replaced by the 40 tokens the response reports
+	if err := limiter.Wait(shortDeadline(t), 61); !errors.Is(err, instructor.ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+	if err := limiter.Wait(shortDeadline(t), 60); err != nil {
+		t.Errorf("err = %v, want the request admitted", err)
+	}
+}
+
+func TestRateLimiterRecordsStreamUsage(t *testing.T) {
+	var body strings.Builder
+	for _, chunk := range []string{`{"items": [`, `{"name":"Joe","age":42}`, `]}`} {
+		b, _ := json.Marshal(chunk)
+		fmt.Fprintf(&body, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%s}}]}\n\n", b)
+	}
+	// OpenAI reports the usage in a last chunk without choices
+	body.WriteString("data: {\"choices\":[],\"usage\":{\"prompt_tokens\":30,\"completion_tokens\":10,\"total_tokens\":40}}\n\n")
+	body.WriteString("data: [DONE]\n\n")
+
+	srv, requests := openaiAPI.start(t, body.String())
+
+	limiter := instructor.NewRateLimiter(0, 100)
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithRateLimiter(limiter),
+	)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Joe is 42"}},
+		Stream:   true,
+	}, *new(Person))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var people []*Person
+	for item := range stream {
+		people = append(people, item.(*Person))
+	}
+	if len(people) != 1 || people[0].Name != "Joe" {
+		t.Fatalf("people = %v", people)
+	}
+
+	if options, _ := (*requests)[0]["stream_options"].(map[string]any); options["include_usage"] != true {
+		t.Errorf("stream_options = %v", (*requests)[0]["stream_options"])
+	}
+
+	// the estimate is replaced by the 40 tokens the stream reports
+	if err := limiter.Wait(shortDeadline(t), 61); !errors.Is(err, instructor.ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+	if err := limiter.Wait(shortDeadline(t), 60); err != nil {
+		t.Errorf("err = %v, want the request admitted", err)
+	}
+}
+
+func TestRateLimiterNil(t *testing.T) {
+	var limiter *instructor.RateLimiter
+	if err := limiter.Wait(context.Background(), 1); err != nil {
+		t.Error(err)
+	}
+}
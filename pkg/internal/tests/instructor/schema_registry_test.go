@@ -0,0 +1,72 @@
+package instructor_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Invoice struct {
+	Number string     `json:"number"`
+	Lines  []LineItem `json:"lines"`
+}
+
+type LineItem struct {
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+func TestSchemaOfCached(t *testing.T) {
+	schema, err := instructor.SchemaOf(Invoice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// pointers share the schema of their type
+	pointer, err := instructor.SchemaOf(&Invoice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pointer != schema {
+		t.Errorf("*Invoice has a schema of its own")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s, err := instructor.SchemaOf(Invoice{}); err != nil || s != schema {
+				t.Errorf("concurrent SchemaOf returned %p, %v", s, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(schema.Functions) != 1 || schema.Functions[0].Name != "Invoice" {
+		t.Errorf("functions = %+v", schema.Functions)
+	}
+}
+
+func TestGoogleAISchemaOfCached(t *testing.T) {
+	if err := instructor.RegisterSchemas(Invoice{}); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := instructor.GoogleAISchemaOf(Invoice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := instructor.GoogleAISchemaOf(&Invoice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("schema generated again")
+	}
+
+	if lines := first.Properties["lines"]; lines == nil || lines.Items == nil || lines.Items.Properties["amount"] == nil {
+		t.Errorf("lines = %+v", lines)
+	}
+}
@@ -0,0 +1,95 @@
+package instructor_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Category struct {
+	Name     string     `json:"name"`
+	Children []Category `json:"children"`
+}
+
+type Extraction struct {
+	Title string `json:"title"`
+}
+
+func (Extraction) InstructorName() string        { return "extract page title" }
+func (Extraction) InstructorDescription() string { return "Extract the title of the page" }
+
+func reflectSchema(t *testing.T, v any) []instructor.FunctionDefinition {
+	t.Helper()
+	return instructor.ToFunctionSchema(reflect.TypeOf(v), (&jsonschema.Reflector{}).Reflect(v))
+}
+
+func TestToFunctionSchemaRoot(t *testing.T) {
+	functions := reflectSchema(t, Invoice{})
+
+	if len(functions) != 1 {
+		t.Fatalf("got %d functions, want the root type only", len(functions))
+	}
+	invoice := functions[0]
+	if invoice.Name != "Invoice" {
+		t.Errorf("name = %q", invoice.Name)
+	}
+	if invoice.Parameters.Properties.Len() != 2 {
+		t.Errorf("parameters = %+v", invoice.Parameters)
+	}
+	if _, ok := invoice.Parameters.Definitions["LineItem"]; !ok {
+		t.Errorf("nested type not kept under $defs: %v", invoice.Parameters.Definitions)
+	}
+	if _, ok := invoice.Parameters.Definitions["Invoice"]; ok {
+		t.Errorf("root type repeated under $defs")
+	}
+}
+
+func TestToFunctionSchemaRecursive(t *testing.T) {
+	functions := reflectSchema(t, Category{})
+
+	if len(functions) != 1 {
+		t.Fatalf("got %d functions", len(functions))
+	}
+	if _, ok := functions[0].Parameters.Definitions["Category"]; !ok {
+		t.Errorf("definition of a recursive root dropped")
+	}
+}
+
+func TestToFunctionSchemaNamer(t *testing.T) {
+	functions := reflectSchema(t, Extraction{})
+
+	if functions[0].Name != "extract_page_title" {
+		t.Errorf("name = %q, want the sanitized InstructorName", functions[0].Name)
+	}
+	if functions[0].Description != "Extract the title of the page" {
+		t.Errorf("description = %q", functions[0].Description)
+	}
+}
+
+func TestOpenAIForcedToolCall(t *testing.T) {
+	srv, requests := openaiAPI.start(t, toolCallCompletion("Person", `"{\"name\":\"Joe\",\"age\":42}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	var person Person
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &person); err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "Joe" {
+		t.Errorf("got %+v", person)
+	}
+
+	request := (*requests)[0]
+	if tools, _ := request["tools"].([]any); len(tools) != 1 {
+		t.Errorf("got %d tools, want 1", len(tools))
+	}
+	choice, _ := request["tool_choice"].(map[string]any)
+	if function, _ := choice["function"].(map[string]any); choice["type"] != "function" || function["name"] != "Person" {
+		t.Errorf("tool_choice = %v, want the Person function", request["tool_choice"])
+	}
+}
@@ -0,0 +1,84 @@
+package instructor_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// cannedAPI is a fake provider API answering requests to path with canned
+// bodies in turn.
+type cannedAPI struct {
+	path string
+	// authorization is the Authorization header requests must carry, if set
+	authorization string
+	// status and exhausted answer the requests made once the bodies run out
+	status    int
+	exhausted string
+}
+
+// openaiAPI is the OpenAI chat completions API.
+var openaiAPI = cannedAPI{
+	path:      "/v1/chat/completions",
+	status:    http.StatusInternalServerError,
+	exhausted: `{"error":{"message":"no more responses"}}`,
+}
+
+// anthropicAPI is the Anthropic messages API.
+var anthropicAPI = cannedAPI{
+	path:      "/messages",
+	status:    http.StatusInternalServerError,
+	exhausted: `{"type":"error","error":{"type":"api_error","message":"no more responses"}}`,
+}
+
+// openaiConfig returns the config of an OpenAI client for srv.
+func openaiConfig(srv *httptest.Server) openai.ClientConfig {
+	config := openai.DefaultConfig("key")
+	config.BaseURL = srv.URL + "/v1"
+	return config
+}
+
+// personCompletion is a completion answering with Joe, 42.
+const personCompletion = `{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"{\"name\":\"Joe\",\"age\":42}"}}],"usage":{"prompt_tokens":30,"completion_tokens":10,"total_tokens":40}}`
+
+// toolCallCompletion is a completion calling the tool name with arguments.
+func toolCallCompletion(name string, arguments string) string {
+	return `{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"` + name + `","arguments":` + arguments + `}}]}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`
+}
+
+// start serves the API with the given bodies, recording the requests it
+// received.
+func (api cannedAPI) start(t *testing.T, bodies ...string) (*httptest.Server, *[]map[string]any) {
+	t.Helper()
+
+	var requests []map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != api.path {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if api.authorization != "" && r.Header.Get("Authorization") != api.authorization {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		requests = append(requests, request)
+
+		if len(requests) > len(bodies) {
+			w.WriteHeader(api.status)
+			fmt.Fprint(w, api.exhausted)
+			return
+		}
+		fmt.Fprint(w, bodies[len(requests)-1])
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
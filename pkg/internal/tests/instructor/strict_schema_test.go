@@ -0,0 +1,112 @@
+package instructor_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/invopop/jsonschema"
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Signup struct {
+	Email    string `json:"email" jsonschema:"format=email"`
+	Age      int    `json:"age,omitempty" jsonschema:"minimum=13"`
+	Referrer string `json:"referrer,omitempty"`
+}
+
+type Scores struct {
+	ByPlayer map[string]int `json:"by_player"`
+}
+
+func TestToStrictSchema(t *testing.T) {
+	strict, err := instructor.ToStrictSchema((&jsonschema.Reflector{}).Reflect(Signup{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(strict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		Defs map[string]struct {
+			Required             []string                   `json:"required"`
+			AdditionalProperties bool                       `json:"additionalProperties"`
+			Properties           map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	signup := schema.Defs["Signup"]
+	if len(signup.Required) != 3 {
+		t.Errorf("required = %v, want every property", signup.Required)
+	}
+	if signup.AdditionalProperties {
+		t.Errorf("additional properties allowed")
+	}
+
+	email := string(signup.Properties["email"])
+	if strings.Contains(email, `"format"`) || !strings.Contains(email, "email") {
+		t.Errorf("email = %s, want the format as a description hint", email)
+	}
+	age := string(signup.Properties["age"])
+	if strings.Contains(age, "minimum") || !strings.Contains(age, `"anyOf"`) || !strings.Contains(age, `"null"`) {
+		t.Errorf("age = %s, want a nullable integer without minimum", age)
+	}
+}
+
+func TestToStrictSchemaIncompatible(t *testing.T) {
+	_, err := instructor.ToStrictSchema((&jsonschema.Reflector{}).Reflect(Scores{}))
+
+	var strictErr *instructor.StrictSchemaError
+	if !errors.As(err, &strictErr) || len(strictErr.Issues) == 0 {
+		t.Fatalf("err = %v, want a StrictSchemaError", err)
+	}
+	if !strings.Contains(err.Error(), "by_player") {
+		t.Errorf("err = %v, want the map field named", err)
+	}
+}
+
+func TestJSONStrictMode(t *testing.T) {
+	srv, requests := openaiAPI.start(t, contentCompletion(`"{\"Signup\":{\"email\":\"joe@example.com\",\"age\":null,\"referrer\":null}}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSONStrict))
+
+	var signup Signup
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &signup); err != nil {
+		t.Fatal(err)
+	}
+	if signup.Email != "joe@example.com" || signup.Age != 0 {
+		t.Errorf("got %+v", signup)
+	}
+
+	format := (*requests)[0]["response_format"].(map[string]any)
+	schema, _ := format["json_schema"].(map[string]any)
+	if format["type"] != "json_schema" || schema["strict"] != true {
+		t.Errorf("response_format = %v", format)
+	}
+}
+
+func TestJSONStrictModeIncompatible(t *testing.T) {
+	srv, requests := openaiAPI.start(t)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSONStrict))
+
+	var scores Scores
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &scores)
+
+	var strictErr *instructor.StrictSchemaError
+	if !errors.As(err, &strictErr) {
+		t.Errorf("err = %v, want a StrictSchemaError", err)
+	}
+	if len(*requests) != 0 {
+		t.Errorf("sent %d requests, want none", len(*requests))
+	}
+}
@@ -0,0 +1,61 @@
+package instructor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+func TestStructuredOutputs(t *testing.T) {
+	srv, requests := openaiAPI.start(t, personCompletion)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeStructuredOutputs))
+
+	var person Person
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Joe is 42"}},
+	}, &person); err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+
+	request := (*requests)[0]
+	format := request["response_format"].(map[string]any)
+	schema, _ := format["json_schema"].(map[string]any)
+	if format["type"] != "json_schema" || schema["name"] != "Person" || schema["strict"] != true {
+		t.Errorf("response_format = %v", format)
+	}
+	// the schema is enforced natively, not repeated in the prompt
+	if messages := request["messages"].([]any); len(messages) != 1 {
+		t.Errorf("sent %d messages, want 1", len(messages))
+	}
+}
+
+func TestStructuredOutputsRefusal(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":null,"refusal":"I can't help with that."}}],"usage":{"prompt_tokens":30,"completion_tokens":8,"total_tokens":38}}`,
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeStructuredOutputs))
+
+	var person Person
+	resp, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &person)
+
+	var refusal *instructor.RefusalError
+	if !errors.As(err, &refusal) || refusal.Refusal != "I can't help with that." {
+		t.Fatalf("err = %v, want a RefusalError", err)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("sent %d requests, refusals aren't retried", len(*requests))
+	}
+	if resp.Usage.TotalTokens != 38 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
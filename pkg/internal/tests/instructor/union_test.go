@@ -0,0 +1,172 @@
+package instructor_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Document interface {
+	isDocument()
+}
+
+type Bill struct {
+	Number string  `json:"number"`
+	Total  float64 `json:"total"`
+}
+
+type Receipt struct {
+	Store string  `json:"store"`
+	Total float64 `json:"total"`
+}
+
+type Memo struct {
+	Subject string `json:"subject"`
+}
+
+func (Bill) isDocument()    {}
+func (Receipt) isDocument() {}
+func (Memo) isDocument()    {}
+
+type Inbox struct {
+	Documents []Document `json:"documents"`
+}
+
+func registerDocument(t *testing.T) {
+	t.Helper()
+	if err := instructor.RegisterUnion[Document]("kind", Bill{}, Receipt{}, Memo{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnionToolCall(t *testing.T) {
+	registerDocument(t)
+
+	srv, requests := openaiAPI.start(t, toolCallCompletion("Receipt", `"{\"store\":\"Corner shop\",\"total\":4.5}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeToolCall))
+
+	var document Document
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	if receipt, ok := document.(Receipt); !ok || receipt.Store != "Corner shop" || receipt.Total != 4.5 {
+		t.Errorf("document = %#v", document)
+	}
+
+	// one tool per variant, without the discriminator
+	var names []string
+	for _, tool := range (*requests)[0]["tools"].([]any) {
+		function := tool.(map[string]any)["function"].(map[string]any)
+		names = append(names, function["name"].(string))
+
+		properties := function["parameters"].(map[string]any)["properties"].(map[string]any)
+		if _, ok := properties["kind"]; ok {
+			t.Errorf("tool %s has the discriminator: %v", function["name"], properties)
+		}
+	}
+	if !slices.Equal(names, []string{"Bill", "Receipt", "Memo"}) {
+		t.Errorf("tools = %v", names)
+	}
+}
+
+func TestUnionField(t *testing.T) {
+	registerDocument(t)
+
+	srv, requests := openaiAPI.start(t, contentCompletion(`"{\"documents\":[{\"kind\":\"Memo\",\"subject\":\"Lunch\"},{\"kind\":\"Bill\",\"number\":\"7\",\"total\":12.5}]}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)), instructor.WithMode(instructor.ModeJSONSchema))
+
+	var inbox Inbox
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &inbox); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inbox.Documents) != 2 {
+		t.Fatalf("documents = %#v", inbox.Documents)
+	}
+	if memo, ok := inbox.Documents[0].(Memo); !ok || memo.Subject != "Lunch" {
+		t.Errorf("documents[0] = %#v", inbox.Documents[0])
+	}
+	if bill, ok := inbox.Documents[1].(Bill); !ok || bill.Number != "7" || bill.Total != 12.5 {
+		t.Errorf("documents[1] = %#v", inbox.Documents[1])
+	}
+
+	messages := (*requests)[0]["messages"].([]any)
+	system := messages[0].(map[string]any)["content"].(string)
+	if !strings.Contains(system, `"oneOf"`) || !strings.Contains(system, `"kind"`) {
+		t.Errorf("schema has no union:\n%s", system)
+	}
+}
+
+func TestUnionUnknownVariant(t *testing.T) {
+	registerDocument(t)
+
+	srv, requests := openaiAPI.start(t,
+		contentCompletion(`"{\"kind\":\"Postcard\",\"subject\":\"Hi\"}"`),
+		contentCompletion(`"{\"kind\":\"Memo\",\"subject\":\"Hi\"}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(1),
+	)
+
+	var document Document
+	if _, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &document); err != nil {
+		t.Fatal(err)
+	}
+	if memo, ok := document.(Memo); !ok || memo.Subject != "Hi" {
+		t.Errorf("document = %#v", document)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("sent %d requests, want 2", len(*requests))
+	}
+}
+
+func TestUnionMissingDiscriminator(t *testing.T) {
+	registerDocument(t)
+
+	srv, _ := openaiAPI.start(t, contentCompletion(`"{\"subject\":\"Hi\"}"`))
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithMaxRetries(0),
+	)
+
+	var document Document
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{Model: openai.GPT4o}, &document)
+	if err == nil || !strings.Contains(err.Error(), `missing discriminator "kind"`) {
+		t.Errorf("err = %v, want the missing discriminator error", err)
+	}
+}
+
+func TestUnionGoogleAISchema(t *testing.T) {
+	registerDocument(t)
+
+	schema, err := instructor.GoogleAISchemaOf(Inbox{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// genai has no oneOf, the variants are merged into one object
+	document := schema.Properties["documents"].Items
+	if kind := document.Properties["kind"]; kind == nil || !slices.Equal(kind.Enum, []string{"Bill", "Receipt", "Memo"}) {
+		t.Errorf("kind = %+v", kind)
+	}
+	if !slices.Equal(document.Required, []string{"kind"}) {
+		t.Errorf("required = %v", document.Required)
+	}
+	if total := document.Properties["total"]; total == nil || !total.Nullable || !strings.Contains(total.Description, "Only for Bill, Receipt") {
+		t.Errorf("total = %+v", total)
+	}
+	if subject := document.Properties["subject"]; subject == nil || !strings.Contains(subject.Description, "Only for Memo") {
+		t.Errorf("subject = %+v", subject)
+	}
+}
@@ -0,0 +1,48 @@
+package instructor_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor"
+)
+
+type Contact struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func TestValidationReask(t *testing.T) {
+	srv, requests := openaiAPI.start(t,
+		contentCompletion(`"{\"email\":\"joe\"}"`),
+		contentCompletion(`"{\"email\":\"joe@example.com\"}"`),
+	)
+
+	client := instructor.FromOpenAI(openai.NewClientWithConfig(openaiConfig(srv)),
+		instructor.WithMode(instructor.ModeJSON),
+		instructor.WithValidation(),
+	)
+
+	var contact Contact
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Sign up joe"}},
+	}, &contact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contact.Email != "joe@example.com" {
+		t.Errorf("email = %q", contact.Email)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(*requests))
+	}
+	messages := (*requests)[1]["messages"].([]any)
+	feedback := messages[len(messages)-1].(map[string]any)["content"].(string)
+	if !strings.Contains(feedback, "Email") || !strings.Contains(feedback, "'email' tag") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
@@ -0,0 +1,221 @@
+package instructor_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"golang.org/x/oauth2"
+
+	"github.com/binarycraft007/instructor-go/pkg/instructor/googleai"
+)
+
+func TestVertexAIClient(t *testing.T) {
+	var request map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/my-project/locations/europe-west4/publishers/google/models/gemini-1.5-pro:generateContent" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("$alt") != "json" {
+			t.Errorf("enums requested as numbers: %s", r.URL.RawQuery)
+		}
+		if r.Header.Get("Authorization") != "Bearer adc-token" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"candidates": [{"content": {"role": "model", "parts": [{"text": "{\"name\":\"Joe\",\"age\":42}"}]}, "finishReason": "STOP", "safetyRatings": [{"category": "HARM_CATEGORY_HATE_SPEECH", "probability": "NEGLIGIBLE"}]}], "usageMetadata": {"promptTokenCount": 30, "candidatesTokenCount": 10, "totalTokenCount": 40}}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	genaiClient, err := googleai.NewVertexClient(ctx, "my-project", "europe-west4",
+		googleai.WithBaseURL(srv.URL),
+		googleai.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer genaiClient.Close()
+
+	model := genaiClient.GenerativeModel("gemini-1.5-pro")
+	model.SafetySettings = []*genai.SafetySetting{{
+		Category:  genai.HarmCategoryDangerousContent,
+		Threshold: genai.HarmBlockOnlyHigh,
+	}}
+
+	resp, err := model.GenerateContent(ctx, genai.Text("Joe is 42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var person Person
+	if err := json.Unmarshal([]byte(resp.Candidates[0].Content.Parts[0].(genai.Text)), &person); err != nil {
+		t.Fatal(err)
+	}
+
+	if person.Name != "Joe" || person.Age != 42 {
+		t.Errorf("got %+v", person)
+	}
+	if resp.UsageMetadata.TotalTokenCount != 40 {
+		t.Errorf("usage = %+v", resp.UsageMetadata)
+	}
+	if rating := resp.Candidates[0].SafetyRatings[0]; rating.Category != genai.HarmCategoryHateSpeech {
+		t.Errorf("category = %v", rating.Category)
+	}
+
+	if request["model"] != "projects/my-project/locations/europe-west4/publishers/google/models/gemini-1.5-pro" {
+		t.Errorf("model = %v", request["model"])
+	}
+	settings, _ := request["safetySettings"].([]any)
+	if setting, _ := settings[0].(map[string]any); setting["category"] != float64(2) {
+		t.Errorf("harm category not renumbered: %v", settings)
+	}
+}
+
+// vertexRequest is a request received by a fake Vertex AI server.
+type vertexRequest struct {
+	method string
+	path   string
+	body   map[string]any
+}
+
+// vertexClient returns a genai client for a fake Vertex AI server in
+// us-central1 answering with body, and the requests the server received.
+func vertexClient(t *testing.T, body string) (*genai.Client, *[]vertexRequest) {
+	t.Helper()
+
+	var requests []vertexRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := vertexRequest{method: r.Method, path: r.URL.Path}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&request.body); err != nil {
+				t.Errorf("decoding request: %v", err)
+			}
+		}
+		requests = append(requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := googleai.NewVertexClient(context.Background(), "my-project", "us-central1",
+		googleai.WithBaseURL(srv.URL),
+		googleai.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "adc-token"})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return client, &requests
+}
+
+const vertexModel = "projects/my-project/locations/us-central1/publishers/google/models/gemini-1.5-pro"
+
+func TestVertexAICountTokens(t *testing.T) {
+	client, requests := vertexClient(t, `{"totalTokens": 5}`)
+
+	model := client.GenerativeModel("gemini-1.5-pro")
+	model.SystemInstruction = genai.NewUserContent(genai.Text("Extract people"))
+	model.SafetySettings = []*genai.SafetySetting{{
+		Category:  genai.HarmCategoryHarassment,
+		Threshold: genai.HarmBlockOnlyHigh,
+	}}
+
+	resp, err := model.CountTokens(context.Background(), genai.Text("Joe is 42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.TotalTokens != 5 {
+		t.Errorf("total tokens = %d", resp.TotalTokens)
+	}
+
+	request := (*requests)[0]
+	if request.path != "/v1/"+vertexModel+":countTokens" {
+		t.Errorf("unexpected path %s", request.path)
+	}
+	if request.body["model"] != vertexModel {
+		t.Errorf("model = %v", request.body["model"])
+	}
+	if _, ok := request.body["generateContentRequest"]; ok {
+		t.Errorf("generateContentRequest not flattened: %v", request.body)
+	}
+	if _, ok := request.body["safetySettings"]; ok {
+		t.Errorf("safety settings sent to countTokens: %v", request.body)
+	}
+	if _, ok := request.body["contents"]; !ok {
+		t.Errorf("no contents: %v", request.body)
+	}
+	if _, ok := request.body["systemInstruction"]; !ok {
+		t.Errorf("no system instruction: %v", request.body)
+	}
+}
+
+func TestVertexAIStreamGenerateContent(t *testing.T) {
+	client, requests := vertexClient(t, `[]`)
+
+	model := client.GenerativeModel("gemini-1.5-flash")
+	model.SafetySettings = []*genai.SafetySetting{
+		{Category: genai.HarmCategoryHarassment, Threshold: genai.HarmBlockOnlyHigh},
+		{Category: genai.HarmCategoryHateSpeech, Threshold: genai.HarmBlockOnlyHigh},
+		{Category: genai.HarmCategorySexuallyExplicit, Threshold: genai.HarmBlockOnlyHigh},
+	}
+
+	// only the request matters, whatever the stream decodes to
+	model.GenerateContentStream(context.Background(), genai.Text("Joe is 42")).Next()
+
+	if len(*requests) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(*requests))
+	}
+	request := (*requests)[0]
+	if want := "/v1/projects/my-project/locations/us-central1/publishers/google/models/gemini-1.5-flash:streamGenerateContent"; request.path != want {
+		t.Errorf("path = %s, want %s", request.path, want)
+	}
+
+	settings, _ := request.body["safetySettings"].([]any)
+	var categories []any
+	for _, s := range settings {
+		categories = append(categories, s.(map[string]any)["category"])
+	}
+	if want := []any{float64(3), float64(1), float64(4)}; fmt.Sprint(categories) != fmt.Sprint(want) {
+		t.Errorf("categories = %v, want %v", categories, want)
+	}
+}
+
+func TestVertexAIModelInfo(t *testing.T) {
+	client, requests := vertexClient(t, `{"name": "`+vertexModel+`"}`)
+
+	if _, err := client.GenerativeModel("gemini-1.5-pro").Info(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	request := (*requests)[0]
+	if request.method != http.MethodGet || request.path != "/v1/"+vertexModel {
+		t.Errorf("got %s %s", request.method, request.path)
+	}
+}
+
+func TestVertexAIFilesNotAvailable(t *testing.T) {
+	client, requests := vertexClient(t, `{}`)
+
+	_, err := client.GetFile(context.Background(), "files/abc")
+	if err == nil || !strings.Contains(err.Error(), "is not available on Vertex AI") {
+		t.Errorf("err = %v, want the not available error", err)
+	}
+	if len(*requests) != 0 {
+		t.Errorf("sent %d requests, want 0", len(*requests))
+	}
+}